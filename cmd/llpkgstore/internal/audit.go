@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/goplus/llpkgstore/internal/actions"
+	"github.com/spf13/cobra"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Verify the invariants of the whole llpkgstore.json",
+	Long: `Verify the invariants of the whole mapping table, e.g.
+
+	llpkgstore audit --store llpkgstore.json --repo .
+
+It checks that mapped versions are unique per package, the order of C versions
+agrees with the order of mapped versions, no patch maintenance happens on a
+superseded legacy patch line, and every mapped version has its clib/vX.Y.Z tag
+in the git repository (skipped with --skip-tags).
+
+The report is printed as JSON, and it exits with an error if any issue is found.`,
+	Args: cobra.NoArgs,
+	RunE: runAuditCmd,
+}
+
+func runAuditCmd(cmd *cobra.Command, args []string) error {
+	store, err := cmd.Flags().GetString("store")
+	if err != nil {
+		return err
+	}
+	repoDir, err := cmd.Flags().GetString("repo")
+	if err != nil {
+		return err
+	}
+	skipTags, err := cmd.Flags().GetBool("skip-tags")
+	if err != nil {
+		return err
+	}
+	if store == "" {
+		return fmt.Errorf("empty path of llpkgstore.json")
+	}
+	m, err := loadStore(store)
+	if err != nil {
+		return err
+	}
+
+	var listTags actions.TagLister
+	if !skipTags {
+		listTags = actions.GitTags(repoDir)
+	}
+	report, err := actions.Audit(m, listTags)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(&report, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+	if !report.OK() {
+		return fmt.Errorf("%d issues found in %s", len(report.Issues), store)
+	}
+	return nil
+}
+
+func init() {
+	auditCmd.Flags().StringP("store", "s", "llpkgstore.json", "Path to llpkgstore.json")
+	auditCmd.Flags().StringP("repo", "r", ".", "Path to the llpkg repository for checking tags")
+	auditCmd.Flags().Bool("skip-tags", false, "Skip checking tags")
+	rootCmd.AddCommand(auditCmd)
+}
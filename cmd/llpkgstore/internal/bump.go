@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/goplus/llpkgstore/config"
+	"github.com/goplus/llpkgstore/internal/actions"
+	"github.com/goplus/llpkgstore/internal/actions/versions"
+	"github.com/goplus/llpkgstore/metadata"
+	"github.com/spf13/cobra"
+)
+
+var bumpCmd = &cobra.Command{
+	Use:   "bump [dir]",
+	Short: "Suggest the next mapped version of an llpkg",
+	Long: `Suggest the mapped version for the llpkg in dir (the current directory by default),
+by applying the version mapping rules to its llpkg.cfg and the history in llpkgstore.json, e.g.
+
+	llpkgstore bump cjson --store ../llpkgstore.json
+
+It prints the reasoning and the Release-as trailer to add to the commit message.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runBumpCmd,
+}
+
+func runBumpCmd(cmd *cobra.Command, args []string) error {
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	store, err := cmd.Flags().GetString("store")
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.ParseLLPkgConfig(filepath.Join(dir, config.LLPkgConfigFileName))
+	if err != nil {
+		return err
+	}
+
+	// versions.Read creates the file if it doesn't exist, no history means the first release
+	ver := &versions.Versions{MetadataMap: metadata.MetadataMap{}}
+	if _, err := os.Stat(store); err == nil {
+		ver, err = versions.Read(store)
+		if err != nil {
+			return err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	suggestion, err := actions.SuggestVersion(ver, cfg)
+	if err != nil {
+		return err
+	}
+	for _, reason := range suggestion.Reasons {
+		fmt.Printf("- %s\n", reason)
+	}
+	if suggestion.Branch != "" {
+		fmt.Printf("\nSubmit it to the branch %s, with the following trailer in your commit message:\n\n\t%s\n",
+			suggestion.Branch, suggestion.Trailer())
+	} else {
+		fmt.Printf("\nAdd the following trailer to your commit message:\n\n\t%s\n", suggestion.Trailer())
+	}
+	return nil
+}
+
+func init() {
+	bumpCmd.Flags().StringP("store", "s", "llpkgstore.json", "Path to llpkgstore.json")
+	rootCmd.AddCommand(bumpCmd)
+}
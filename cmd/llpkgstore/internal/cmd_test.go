@@ -1,11 +1,15 @@
 package internal
 
 import (
+	"crypto/ed25519"
+	"encoding/base64"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/goplus/llpkgstore/internal/actions/versions"
 	"github.com/goplus/llpkgstore/internal/file"
+	"github.com/goplus/llpkgstore/metadata"
 )
 
 func TestCMD(t *testing.T) {
@@ -20,3 +24,81 @@ func TestCMD(t *testing.T) {
 	file.CopyFilePattern(demoDir, filepath.Dir(demoDir), "*.pc")
 	runDemotestCmd(nil, nil)
 }
+
+func TestParsePackageSpec(t *testing.T) {
+	clib, cversion, err := parsePackageSpec("cjson@1.7.18")
+	if err != nil || clib != "cjson" || cversion != "1.7.18" {
+		t.Errorf("unexpected result: %s %s %v", clib, cversion, err)
+	}
+	for _, spec := range []string{"cjson", "cjson@", "@1.7.18", "cjson@1.8.0-beta.1"} {
+		if _, _, err := parsePackageSpec(spec); err == nil {
+			t.Errorf("unexpected behavior: %s is accepted", spec)
+		}
+	}
+}
+
+func TestRetractSignedStore(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := filepath.Join(t.TempDir(), "llpkgstore.json")
+	ver, err := versions.Read(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ver.Write("cjson", "1.7.18", "v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ver.Sign(priv); err != nil {
+		t.Fatal(err)
+	}
+
+	// re-signed with the key
+	t.Setenv("LLPKGSTORE_SIGNING_KEY", base64.StdEncoding.EncodeToString(priv.Seed()))
+	if err := retract(store, "cjson", "v1.0.0", "broken binary"); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := os.ReadFile(store)
+	sig, _ := os.ReadFile(store + metadata.SignatureSuffix)
+	if err := metadata.Verify([]ed25519.PublicKey{pub}, b, sig); err != nil {
+		t.Errorf("unexpected signature: %v", err)
+	}
+
+	// the stale signature is removed without the key
+	t.Setenv("LLPKGSTORE_SIGNING_KEY", "")
+	if err := ver.Write("cjson", "1.7.18", "v1.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := retract(store, "cjson", "v1.0.1", "broken binary"); err == nil {
+		t.Error("unexpected behavior: stale signature is kept silently")
+	}
+	if _, err := os.Stat(store + metadata.SignatureSuffix); err == nil {
+		t.Error("unexpected behavior: stale signature is kept")
+	}
+}
+
+func TestLoadSiteConfigs(t *testing.T) {
+	configDir := filepath.Join(t.TempDir(), "llpkg")
+	if err := os.MkdirAll(filepath.Join(configDir, "cjson"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := `{"upstream":{"package":{"name":"cjson","version":"1.7.18"}}}`
+	os.WriteFile(filepath.Join(configDir, "cjson", "llpkg.cfg"), []byte(cfg), 0644)
+	// outside the config directory
+	os.WriteFile(filepath.Join(filepath.Dir(configDir), "llpkg.cfg"), []byte(cfg), 0644)
+
+	configs, err := loadSiteConfigs(configDir, metadata.MetadataMap{"cjson": {}, "zlib": {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := configs["cjson"]; !ok || len(configs) != 1 {
+		t.Errorf("unexpected configs: %v", configs)
+	}
+
+	for _, name := range []string{"..", "../cjson", ".hidden", "a/b"} {
+		if _, err := loadSiteConfigs(configDir, metadata.MetadataMap{name: {}}); err == nil {
+			t.Errorf("unexpected behavior: %q is accepted", name)
+		}
+	}
+}
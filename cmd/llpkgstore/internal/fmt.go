@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/goplus/llpkgstore/config"
+	"github.com/spf13/cobra"
+)
+
+var fmtCmd = &cobra.Command{
+	Use:   "fmt [LLPkgDir...]",
+	Short: "Format llpkg.cfg and llcppg.cfg",
+	Long: `Rewrite llpkg.cfg and llcppg.cfg in canonical key order and indentation,
+dropping fields with default values. By default, use current dir.
+
+With --check, files are not rewritten, unformatted files are listed and
+the command fails if there's any.`,
+	RunE: runFmtCmd,
+}
+
+func runFmtCmd(cmd *cobra.Command, args []string) error {
+	check, err := cmd.Flags().GetBool("check")
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		args = append(args, currentDir())
+	}
+
+	var unformatted []string
+	for _, dir := range args {
+		for _, name := range []string{config.LLPkgConfigFileName, config.LLCppgConfigFileName} {
+			path := filepath.Join(dir, name)
+			src, err := os.ReadFile(path)
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			formatted, err := config.Format(name, src)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			if bytes.Equal(src, formatted) {
+				continue
+			}
+			fmt.Println(path)
+			if check {
+				unformatted = append(unformatted, path)
+				continue
+			}
+			if err := os.WriteFile(path, formatted, 0644); err != nil {
+				return err
+			}
+		}
+	}
+	if len(unformatted) > 0 {
+		return fmt.Errorf("%d config files are not formatted, run llpkgstore fmt to fix them", len(unformatted))
+	}
+	return nil
+}
+
+func init() {
+	fmtCmd.Flags().Bool("check", false, "Report unformatted files without rewriting them")
+	rootCmd.AddCommand(fmtCmd)
+}
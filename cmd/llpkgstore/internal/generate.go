@@ -29,6 +29,21 @@ func currentDir() string {
 	return dir
 }
 
+// runLLCppcfg tries llcppcfg to generate llcppg.cfg if llcppg.cfg dones't exist in dir
+func runLLCppcfg(dir, pcName, pcDir string) error {
+	if _, err := os.Stat(filepath.Join(dir, "llcppg.cfg")); !os.IsNotExist(err) {
+		return nil
+	}
+	cmd := exec.Command("llcppcfg", pcName)
+	cmd.Dir = dir
+	pc.SetPath(cmd, pcDir)
+	ret, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("llcppcfg execute fail: %s", string(ret))
+	}
+	return nil
+}
+
 func runLLCppgGenerateWithDir(dir string) error {
 	cfg, err := config.ParseLLPkgConfig(filepath.Join(dir, LLGOModuleIdentifyFile))
 	if err != nil {
@@ -54,15 +69,9 @@ func runLLCppgGenerateWithDir(dir string) error {
 	if err != nil {
 		return err
 	}
-	// try llcppcfg if llcppg.cfg dones't exist
-	if _, err := os.Stat(filepath.Join(dir, "llcppg.cfg")); os.IsNotExist(err) {
-		cmd := exec.Command("llcppcfg", pcName[0])
-		cmd.Dir = dir
-		pc.SetPath(cmd, tempDir)
-		ret, err := cmd.CombinedOutput()
-		if err != nil {
-			return fmt.Errorf("llcppcfg execute fail: %s", string(ret))
-		}
+	err = runLLCppcfg(dir, pcName[0], tempDir)
+	if err != nil {
+		return err
 	}
 
 	generator := llcppg.New(dir, cfg.Upstream.Package.Name, tempDir)
@@ -0,0 +1,165 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/goplus/llpkgstore/config"
+	"github.com/goplus/llpkgstore/internal/actions"
+	"github.com/goplus/llpkgstore/internal/actions/versions"
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/semver"
+)
+
+const demoTemplate = `package main
+
+import (
+	_ "github.com/goplus/llpkg/%s"
+)
+
+func main() {
+	// TODO: call the functions of %s here to verify the llpkg works as expected.
+}
+`
+
+var initCmd = &cobra.Command{
+	Use:   "init clib@cversion",
+	Short: "Scaffold a new llpkg",
+	Long: `Scaffold a new llpkg from the upstream package, e.g. llpkgstore init cjson@1.7.18
+
+It creates llpkg.cfg, llcppg.cfg and a demo skeleton in the package directory,
+and proposes the initial Release-as trailer.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runInitCmd,
+}
+
+// parsePackageSpec splits clib@cversion into the library name and version.
+func parsePackageSpec(spec string) (clib, cversion string, err error) {
+	clib, cversion, found := strings.Cut(strings.TrimSpace(spec), "@")
+	if !found || clib == "" || cversion == "" {
+		err = fmt.Errorf("invalid package format: %s, expect clib@cversion", spec)
+		return
+	}
+	if semver.Prerelease(versions.ToSemVer(cversion)) != "" {
+		err = fmt.Errorf("pre-release version %s is not accepted", cversion)
+	}
+	return
+}
+
+func runInitCmd(cmd *cobra.Command, args []string) (err error) {
+	clib, cversion, err := parsePackageSpec(args[0])
+	if err != nil {
+		return err
+	}
+	dir, err := cmd.Flags().GetString("dir")
+	if err != nil {
+		return err
+	}
+	if dir == "" {
+		dir = clib
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(dir, LLGOModuleIdentifyFile)); err == nil {
+		return fmt.Errorf("%s has already existed in %s", LLGOModuleIdentifyFile, dir)
+	}
+
+	cfg := config.LLPkgConfig{
+		Upstream: config.UpstreamConfig{
+			Installer: config.InstallerConfig{Name: config.ValidInstallers[0]},
+			Package:   config.PackageConfig{Name: clib, Version: cversion},
+		},
+	}
+	if err := config.ValidateLLPkgConfig(cfg); err != nil {
+		return err
+	}
+	uc, err := config.NewUpstreamFromConfig(cfg.Upstream)
+	if err != nil {
+		return err
+	}
+
+	exec.Command("conan", "profile", "detect").Run()
+
+	// 1. confirm the package exists in the upstream
+	found, err := uc.Installer.Search(uc.Pkg)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(found, clib+"/"+cversion) {
+		return fmt.Errorf("%s@%s not found in %s, available: %v", clib, cversion, uc.Installer.Name(), found)
+	}
+
+	// 2. install the package and generate llcppg.cfg
+	// llpkg.cfg is written at last, and the files created are removed on error,
+	// so that a failed init can be retried.
+	var created []string
+	defer func() {
+		if err != nil {
+			for i := len(created) - 1; i >= 0; i-- {
+				os.RemoveAll(created[i])
+			}
+		}
+	}()
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		created = append(created, dir)
+	}
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+
+	tempDir, err := os.MkdirTemp("", "llpkg-tool")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+	pcName, err := uc.Installer.Install(uc.Pkg, tempDir)
+	if err != nil {
+		return err
+	}
+	llcppgPath := filepath.Join(dir, config.LLCppgConfigFileName)
+	if _, err := os.Stat(llcppgPath); os.IsNotExist(err) {
+		created = append(created, llcppgPath)
+	}
+	if err := runLLCppcfg(dir, pcName[0], tempDir); err != nil {
+		return err
+	}
+
+	// 3. create the demo skeleton
+	demoDir := filepath.Join(dir, "_demo")
+	if _, err := os.Stat(demoDir); os.IsNotExist(err) {
+		created = append(created, demoDir)
+	}
+	if err := os.MkdirAll(filepath.Join(demoDir, "basic"), 0777); err != nil {
+		return err
+	}
+	demoFile := filepath.Join(demoDir, "basic", "main.go")
+	if _, err := os.Stat(demoFile); os.IsNotExist(err) {
+		created = append(created, demoFile)
+		err = os.WriteFile(demoFile, []byte(fmt.Sprintf(demoTemplate, clib, clib)), 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 4. write llpkg.cfg
+	if err := config.WriteLLPkgConfig(filepath.Join(dir, LLGOModuleIdentifyFile), cfg); err != nil {
+		return err
+	}
+
+	// 5. propose the initial mapped version
+	fmt.Printf("Initialized %s in %s\n", clib, dir)
+	fmt.Printf("Add the following trailer to your commit message:\n\n\t%s%s/%s\n",
+		actions.MappedVersionPrefix, clib, versions.InitialVersion(cversion))
+	return nil
+}
+
+func init() {
+	initCmd.Flags().StringP("dir", "d", "", "Path to the llpkg directory, defaults to ./{clib}")
+	rootCmd.AddCommand(initCmd)
+}
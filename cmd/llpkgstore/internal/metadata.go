@@ -0,0 +1,160 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/goplus/llpkgstore/internal/actions/env"
+	"github.com/goplus/llpkgstore/metadata"
+	"github.com/spf13/cobra"
+)
+
+var metadataCmd = &cobra.Command{
+	Use:   "metadata",
+	Short: "Maintain llpkgstore.json",
+}
+
+var metadataConvertCmd = &cobra.Command{
+	Use:   "convert input output",
+	Short: "Convert the mapping format of llpkgstore.json",
+	Long: `Convert llpkgstore.json between the map format understood by all clients
+and the array format described in the design doc, e.g.
+
+	llpkgstore metadata convert llpkgstore.json llpkgstore.v2.json --format array
+
+The input is never modified, so the converted file can be published alongside
+the original one until old clients are gone.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMetadataConvertCmd,
+}
+
+func runMetadataConvertCmd(cmd *cobra.Command, args []string) error {
+	formatName, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	format, err := metadata.ParseMappingFormat(formatName)
+	if err != nil {
+		return err
+	}
+
+	b, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	var m metadata.MetadataMap
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+
+	converted, err := metadata.Marshal(m, format)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(args[1], converted, 0644)
+}
+
+var metadataDiffCmd = &cobra.Command{
+	Use:   "diff old new",
+	Short: "Report changes between two llpkgstore.json",
+	Long: `Report the added and removed packages, new C versions, and new or retracted
+mapped versions from the old llpkgstore.json to the new one, e.g.
+
+	llpkgstore metadata diff last-week.json llpkgstore.json --format markdown`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMetadataDiffCmd,
+}
+
+func runMetadataDiffCmd(cmd *cobra.Command, args []string) error {
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	if args[0] == "" || args[1] == "" {
+		return fmt.Errorf("empty path of llpkgstore.json")
+	}
+	oldMap, err := loadStore(args[0])
+	if err != nil {
+		return err
+	}
+	newMap, err := loadStore(args[1])
+	if err != nil {
+		return err
+	}
+
+	changes := metadata.Diff(oldMap, newMap)
+	switch format {
+	case "markdown":
+		fmt.Print(changes.Markdown())
+	case "json":
+		b, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	default:
+		return fmt.Errorf("unknown output format: %s, expect markdown or json", format)
+	}
+	return nil
+}
+
+var metadataShardCmd = &cobra.Command{
+	Use:   "shard llpkgstore.json",
+	Short: "Split llpkgstore.json into an index and per-package files",
+	Long: `Write the sharded layout of llpkgstore.json to the output directory, e.g.
+
+	llpkgstore metadata shard llpkgstore.json --out site
+
+which writes site/index.json and site/packages/<name>.json. Files of removed
+packages are deleted. The index is signed when LLPKGSTORE_SIGNING_KEY is set.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMetadataShardCmd,
+}
+
+func runMetadataShardCmd(cmd *cobra.Command, args []string) error {
+	out, err := cmd.Flags().GetString("out")
+	if err != nil {
+		return err
+	}
+	if args[0] == "" {
+		return fmt.Errorf("empty path of llpkgstore.json")
+	}
+	m, err := loadStore(args[0])
+	if err != nil {
+		return err
+	}
+	if err := metadata.WriteShards(m, out); err != nil {
+		return err
+	}
+
+	signingKey, err := env.SigningKey()
+	if err != nil {
+		log.Printf("skip signing %s: %v", metadata.ShardIndexFileName, err)
+		return nil
+	}
+	key, err := metadata.ParsePrivateKey(signingKey)
+	if err != nil {
+		return err
+	}
+	indexPath := filepath.Join(out, metadata.ShardIndexFileName)
+	index, err := os.ReadFile(indexPath)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(indexPath+metadata.SignatureSuffix, metadata.Sign(key, index), 0644)
+}
+
+func init() {
+	metadataShardCmd.Flags().StringP("out", "o", ".", "Output directory")
+	metadataCmd.AddCommand(metadataShardCmd)
+
+	metadataDiffCmd.Flags().StringP("format", "f", "markdown", "Output format, markdown or json")
+	metadataCmd.AddCommand(metadataDiffCmd)
+
+	metadataConvertCmd.Flags().StringP("format", "f", "array", "Mapping format of the output, map or array")
+	metadataCmd.AddCommand(metadataConvertCmd)
+	rootCmd.AddCommand(metadataCmd)
+}
@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/goplus/llpkgstore/internal/goproxy"
+	"github.com/goplus/llpkgstore/metadata"
+	"github.com/spf13/cobra"
+)
+
+var proxyCmd = &cobra.Command{
+	Use:   "proxy",
+	Short: "Serve llpkg modules over the GOPROXY protocol",
+	Long: `Serve the github.com/goplus/llpkg/{clib} modules from a local clone of
+the llpkg repository, using the {clib}/vX.Y.Z tags, e.g.
+
+	llpkgstore proxy --repo ./llpkg --addr :8081
+	GOPROXY=http://{host}:8081 GONOSUMDB=github.com/goplus/llpkg go get github.com/goplus/llpkg/cjson@1.7.18
+
+C version aliases like cjson@1.7.18 are resolved by llpkgstore.json,
+which is {repo}/llpkgstore.json by default.`,
+	Args: cobra.NoArgs,
+	RunE: runProxyCmd,
+}
+
+func runProxyCmd(cmd *cobra.Command, args []string) error {
+	repoDir, err := cmd.Flags().GetString("repo")
+	if err != nil {
+		return err
+	}
+	addr, err := cmd.Flags().GetString("addr")
+	if err != nil {
+		return err
+	}
+	store, err := cmd.Flags().GetString("store")
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, ".git")); err != nil {
+		return err
+	}
+
+	var opts []goproxy.Options
+	if store == "" {
+		store = filepath.Join(repoDir, "llpkgstore.json")
+	}
+	m, err := loadStore(store)
+	switch {
+	case err == nil:
+		opts = append(opts, goproxy.WithMetadata(metadata.NewMemoryManager(m)))
+	case errors.Is(err, os.ErrNotExist):
+		log.Printf("%s doesn't exist, C version aliases are disabled", store)
+	default:
+		return err
+	}
+
+	log.Printf("serving llpkg modules in %s on %s", repoDir, addr)
+	return http.ListenAndServe(addr, goproxy.New(repoDir, opts...))
+}
+
+func init() {
+	proxyCmd.Flags().StringP("repo", "r", ".", "Path to the clone of the llpkg repository")
+	proxyCmd.Flags().StringP("addr", "a", ":8081", "Address to listen on")
+	proxyCmd.Flags().StringP("store", "s", "", "Path to llpkgstore.json, defaults to {repo}/llpkgstore.json")
+	rootCmd.AddCommand(proxyCmd)
+}
@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/goplus/llpkgstore/internal/actions"
+	"github.com/goplus/llpkgstore/internal/actions/env"
+	"github.com/goplus/llpkgstore/internal/actions/versions"
+	"github.com/goplus/llpkgstore/metadata"
+	"github.com/spf13/cobra"
+)
+
+var retractCmd = &cobra.Command{
+	Use:   "retract clib/vX.Y.Z",
+	Short: "Retract a mapped version",
+	Long: `Mark a mapped version as retracted in llpkgstore.json, e.g.
+
+	llpkgstore retract cjson/v1.0.1 --reason "broken binary on darwin/arm64"
+
+Retracted versions are never selected as the latest version by llgo,
+but they're still available if required exactly.
+
+If the store is signed, it's signed again with LLPKGSTORE_SIGNING_KEY.
+Without the key, the stale signature is removed and the command fails.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRetractCmd,
+}
+
+func runRetractCmd(cmd *cobra.Command, args []string) error {
+	reason, err := cmd.Flags().GetString("reason")
+	if err != nil {
+		return err
+	}
+	if reason == "" {
+		return fmt.Errorf("a reason is required for retraction")
+	}
+	store, err := cmd.Flags().GetString("store")
+	if err != nil {
+		return err
+	}
+	clib, mappedVersion, err := actions.ParseMappedVersion(args[0])
+	if err != nil {
+		return err
+	}
+	return retract(store, clib, mappedVersion, reason)
+}
+
+// retract retracts the mapped version in the store, and re-signs the store,
+// so that clients verifying the signature keep accepting it.
+func retract(store, clib, mappedVersion, reason string) error {
+	// versions.Read creates the file if it doesn't exist
+	if _, err := os.Stat(store); err != nil {
+		return err
+	}
+	ver, err := versions.Read(store)
+	if err != nil {
+		return err
+	}
+	if err := ver.Retract(clib, mappedVersion, reason); err != nil {
+		return err
+	}
+
+	signingKey, err := env.SigningKey()
+	if err == nil {
+		key, err := metadata.ParsePrivateKey(signingKey)
+		if err != nil {
+			return err
+		}
+		return ver.Sign(key)
+	}
+	// the signature doesn't match any more, and clients would refuse the store
+	sigFile := store + metadata.SignatureSuffix
+	if _, statErr := os.Stat(sigFile); statErr != nil {
+		return nil
+	}
+	if err := os.Remove(sigFile); err != nil {
+		return err
+	}
+	return fmt.Errorf("%s is removed as it's stale, sign %s again: %w", sigFile, store, err)
+}
+
+func init() {
+	retractCmd.Flags().StringP("reason", "r", "", "Reason of the retraction")
+	retractCmd.Flags().StringP("store", "s", "llpkgstore.json", "Path to llpkgstore.json")
+	rootCmd.AddCommand(retractCmd)
+}
@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/goplus/llpkgstore/metadata"
+	"github.com/spf13/cobra"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search query",
+	Short: "Search llpkgs in the store",
+	Long: `Search llpkgs by name, tags and description, ranked by relevance, e.g.
+
+	llpkgstore search json
+
+It searches the published store by default, cached in {LLGOCACHE}/llpkgstore.json.
+With --store, it searches the local llpkgstore.json instead.`,
+	Args: cobra.ArbitraryArgs,
+	RunE: runSearchCmd,
+}
+
+// llgoCacheDir returns LLGOCACHE, which defaults to {UserCacheDir}/llgo
+func llgoCacheDir() (string, error) {
+	if dir := os.Getenv("LLGOCACHE"); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "llgo"), nil
+}
+
+// loadStore reads the metadata map from a local llpkgstore.json,
+// or the published one if path is empty.
+func loadStore(path string) (metadata.MetadataMap, error) {
+	if path == "" {
+		cacheDir, err := llgoCacheDir()
+		if err != nil {
+			return nil, err
+		}
+		mgr, err := metadata.NewMetadataMgr(cacheDir)
+		if err != nil {
+			return nil, err
+		}
+		return mgr.AllMetadata()
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m metadata.MetadataMap
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+func runSearchCmd(cmd *cobra.Command, args []string) error {
+	store, err := cmd.Flags().GetString("store")
+	if err != nil {
+		return err
+	}
+	m, err := loadStore(store)
+	if err != nil {
+		return err
+	}
+
+	results := metadata.Search(m, strings.Join(args, " "))
+	if len(results) == 0 {
+		return fmt.Errorf("no llpkg found")
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVERSION\tC VERSION\tDESCRIPTION")
+	for _, result := range results {
+		// packages without available versions are still listed
+		resolved, _ := metadata.Resolve(m, result.Name)
+		var description string
+		if info := m[result.Name]; info != nil {
+			description = info.Description
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.Name, resolved.GoVersion, resolved.CVersion, description)
+	}
+	return w.Flush()
+}
+
+func init() {
+	searchCmd.Flags().StringP("store", "s", "", "Path to a local llpkgstore.json, search the published one if empty")
+	rootCmd.AddCommand(searchCmd)
+}
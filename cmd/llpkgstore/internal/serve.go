@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/goplus/llpkgstore/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve llpkgstore.json and the query API over HTTP",
+	Long: `Serve a local llpkgstore.json to host a private store, e.g.
+
+	llpkgstore serve --store llpkgstore.json --addr :8080
+
+and point llgo at http://{host}:8080 as the base URL of the store.
+Besides llpkgstore.json, it serves the query API:
+
+	/api/v1/resolve?q=cjson@1.7
+	/api/v1/packages/cjson
+	/api/v1/search?q=json
+
+The store file is reloaded once it's modified.`,
+	Args: cobra.NoArgs,
+	RunE: runServeCmd,
+}
+
+func runServeCmd(cmd *cobra.Command, args []string) error {
+	store, err := cmd.Flags().GetString("store")
+	if err != nil {
+		return err
+	}
+	addr, err := cmd.Flags().GetString("addr")
+	if err != nil {
+		return err
+	}
+	maxAge, err := cmd.Flags().GetDuration("max-age")
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(store); err != nil {
+		return err
+	}
+
+	log.Printf("serving %s on %s", store, addr)
+	return http.ListenAndServe(addr, server.New(store, server.WithMaxAge(maxAge)))
+}
+
+func init() {
+	serveCmd.Flags().StringP("store", "s", "llpkgstore.json", "Path to llpkgstore.json")
+	serveCmd.Flags().StringP("addr", "a", ":8080", "Address to listen on")
+	serveCmd.Flags().Duration("max-age", 0, "max-age of Cache-Control of llpkgstore.json, zero means clients always revalidate")
+	rootCmd.AddCommand(serveCmd)
+}
@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/goplus/llpkgstore/config"
+	"github.com/goplus/llpkgstore/internal/site"
+	"github.com/goplus/llpkgstore/metadata"
+	"github.com/spf13/cobra"
+)
+
+var siteCmd = &cobra.Command{
+	Use:   "site",
+	Short: "Maintain the llpkg web catalogue",
+}
+
+var siteBuildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Build the static llpkg web catalogue",
+	Long: `Render the static HTML/JS catalogue of llpkg.goplus.org from llpkgstore.json
+and the llpkg.cfg of the packages, e.g.
+
+	llpkgstore site build --store llpkgstore.json --configs . --out _site
+
+The llpkg.cfg of a package is read from {configs}/{name}/llpkg.cfg if it exists.
+llpkgstore.json, and its signature if any, are copied to the output directory.`,
+	Args: cobra.NoArgs,
+	RunE: runSiteBuildCmd,
+}
+
+func runSiteBuildCmd(cmd *cobra.Command, args []string) error {
+	store, err := cmd.Flags().GetString("store")
+	if err != nil {
+		return err
+	}
+	configDir, err := cmd.Flags().GetString("configs")
+	if err != nil {
+		return err
+	}
+	out, err := cmd.Flags().GetString("out")
+	if err != nil {
+		return err
+	}
+
+	b, err := os.ReadFile(store)
+	if err != nil {
+		return err
+	}
+	var m metadata.MetadataMap
+	if err := json.Unmarshal(b, &m); err != nil {
+		return fmt.Errorf("%s: %w", store, err)
+	}
+
+	configs, err := loadSiteConfigs(configDir, m)
+	if err != nil {
+		return err
+	}
+
+	if err := site.Build(out, b, configs); err != nil {
+		return err
+	}
+
+	sig, err := os.ReadFile(store + metadata.SignatureSuffix)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(out, site.StoreFileName+metadata.SignatureSuffix), sig, 0644)
+}
+
+// loadSiteConfigs reads the llpkg.cfg of the packages in configDir, the missing ones are skipped.
+// Package names are validated before reading, so that they never escape configDir.
+func loadSiteConfigs(configDir string, m metadata.MetadataMap) (map[string]config.LLPkgConfig, error) {
+	configs := make(map[string]config.LLPkgConfig)
+	for name := range m {
+		if !metadata.ValidPackageName(name) {
+			return nil, fmt.Errorf("invalid package name: %q", name)
+		}
+		cfg, err := config.ParseLLPkgConfig(filepath.Join(configDir, name, config.LLPkgConfigFileName))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		configs[name] = cfg
+	}
+	return configs, nil
+}
+
+func init() {
+	siteBuildCmd.Flags().StringP("store", "s", "llpkgstore.json", "Path to llpkgstore.json")
+	siteBuildCmd.Flags().StringP("configs", "c", ".", "Directory containing the llpkgs")
+	siteBuildCmd.Flags().StringP("out", "o", "_site", "Output directory")
+	siteCmd.AddCommand(siteBuildCmd)
+	rootCmd.AddCommand(siteCmd)
+}
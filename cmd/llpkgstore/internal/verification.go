@@ -28,6 +28,9 @@ func runLLCppgVerificationWithDir(dir string) error {
 	if err != nil {
 		return fmt.Errorf("parse config error: %v", err)
 	}
+	if err := config.ValidateLLPkgConfig(cfg); err != nil {
+		return err
+	}
 	uc, err := config.NewUpstreamFromConfig(cfg.Upstream)
 	if err != nil {
 		return err
@@ -36,6 +39,20 @@ func runLLCppgVerificationWithDir(dir string) error {
 	if err != nil {
 		return err
 	}
+	llcppgCfg, err := config.ParseLLCppgConfig(filepath.Join(dir, "llcppg.cfg"))
+	if err != nil {
+		return fmt.Errorf("parse config error: %v", err)
+	}
+	if err := config.ValidateLLCppgConfig(llcppgCfg, cfg, dir); err != nil {
+		return err
+	}
+	deps, err := uc.Installer.Dependency(uc.Pkg)
+	if err != nil {
+		return err
+	}
+	if err := config.ValidateDependencies(cfg, deps); err != nil {
+		return err
+	}
 	generator := llcppg.New(dir, cfg.Upstream.Package.Name, dir)
 
 	generated := filepath.Join(dir, ".generated")
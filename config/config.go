@@ -10,8 +10,16 @@ import (
 var ValidInstallers = []string{"conan"}
 
 // LLPkgConfig represents the configuration structure parsed from llpkg.cfg files.
+// Descriptive fields are optional and exported to llpkgstore.json for the website.
 type LLPkgConfig struct {
-	Upstream UpstreamConfig `json:"upstream"`
+	Description string   `json:"description,omitempty"`
+	Homepage    string   `json:"homepage,omitempty"`
+	License     string   `json:"license,omitempty"`
+	Maintainers []string `json:"maintainers,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+
+	Upstream     UpstreamConfig     `json:"upstream"`
+	Dependencies []DependencyConfig `json:"dependencies,omitempty"`
 }
 
 // UpstreamConfig defines the upstream configuration containing installer settings and package metadata.
@@ -34,6 +42,16 @@ type PackageConfig struct {
 	Version string `json:"version"`
 }
 
+// DependencyConfig declares another llpkg which this llpkg depends on.
+// "name" is the C library name of the llpkg (e.g., "zlib").
+// "version" is the minimal mapped version required (e.g., "v1.0.0"), following MVS.
+// Version constraints like the ones of metadata.Resolve aren't accepted,
+// as the requirement is written to go.mod, where only a minimum can be expressed.
+type DependencyConfig struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
 // NewUpstreamFromConfig creates an Upstream instance from configuration data.
 // Returns error if unsupported installer type is specified.
 func NewUpstreamFromConfig(upstreamConfig UpstreamConfig) (*upstream.Upstream, error) {
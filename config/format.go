@@ -0,0 +1,196 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	LLPkgConfigFileName  = "llpkg.cfg"
+	LLCppgConfigFileName = "llcppg.cfg"
+)
+
+// formattedLLPkgConfig is the canonical layout of llpkg.cfg,
+// fields having default values are omitted.
+type formattedLLPkgConfig struct {
+	Description string   `json:"description,omitempty"`
+	Homepage    string   `json:"homepage,omitempty"`
+	License     string   `json:"license,omitempty"`
+	Maintainers []string `json:"maintainers,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+
+	Upstream     formattedUpstreamConfig `json:"upstream"`
+	Dependencies []DependencyConfig      `json:"dependencies,omitempty"`
+}
+
+type formattedUpstreamConfig struct {
+	Installer *formattedInstallerConfig `json:"installer,omitempty"`
+	Package   PackageConfig             `json:"package"`
+}
+
+type formattedInstallerConfig struct {
+	Name   string            `json:"name,omitempty"`
+	Config map[string]string `json:"config,omitempty"`
+}
+
+// formattedLLCppgConfig is the canonical layout of llcppg.cfg,
+// fields having default values are omitted.
+type formattedLLCppgConfig struct {
+	Name         string   `json:"name"`
+	CFlags       string   `json:"cflags"`
+	Libs         string   `json:"libs"`
+	Include      []string `json:"include"`
+	Deps         []string `json:"deps,omitempty"`
+	TrimPrefixes []string `json:"trimPrefixes,omitempty"`
+	Cplusplus    bool     `json:"cplusplus,omitempty"`
+}
+
+// Format returns the canonical form of a config file.
+// The kind of config is determined by the base name of fileName,
+// which must be llpkg.cfg or llcppg.cfg.
+//
+// The canonical form has a fixed key order, two-space indentation and
+// a trailing newline, and it omits the fields whose values are the defaults.
+// Unknown fields of llpkg.cfg are reported as an error instead of being dropped silently,
+// while the ones of llcppg.cfg are kept after the known fields in sorted order,
+// because llcppg accepts more fields than LLCppgConfig models.
+func Format(fileName string, src []byte) ([]byte, error) {
+	switch filepath.Base(fileName) {
+	case LLPkgConfigFileName:
+		return FormatLLPkgConfig(src)
+	case LLCppgConfigFileName:
+		return FormatLLCppgConfig(src)
+	default:
+		return nil, fmt.Errorf("unknown config file: %s", fileName)
+	}
+}
+
+// FormatLLPkgConfig returns the canonical form of llpkg.cfg content.
+func FormatLLPkgConfig(src []byte) ([]byte, error) {
+	var config LLPkgConfig
+	if err := decodeStrict(src, &config); err != nil {
+		return nil, err
+	}
+
+	formatted := formattedLLPkgConfig{
+		Description: config.Description,
+		Homepage:    config.Homepage,
+		License:     config.License,
+		Maintainers: config.Maintainers,
+		Tags:        config.Tags,
+		Upstream: formattedUpstreamConfig{
+			Package: config.Upstream.Package,
+		},
+		Dependencies: config.Dependencies,
+	}
+
+	installer := formattedInstallerConfig{Name: config.Upstream.Installer.Name}
+	// installer.name will be filled by fillDefaults.
+	if installer.Name == ValidInstallers[0] {
+		installer.Name = ""
+	}
+	for k, v := range config.Upstream.Installer.Config {
+		if v == "" {
+			continue
+		}
+		if installer.Config == nil {
+			installer.Config = map[string]string{}
+		}
+		installer.Config[k] = v
+	}
+	if installer.Name != "" || len(installer.Config) > 0 {
+		formatted.Upstream.Installer = &installer
+	}
+
+	return encodeCanonical(&formatted)
+}
+
+// FormatLLCppgConfig returns the canonical form of llcppg.cfg content.
+func FormatLLCppgConfig(src []byte) ([]byte, error) {
+	var config LLCppgConfig
+	if err := decode(src, &config, false); err != nil {
+		return nil, err
+	}
+	return formatLLCppgConfig(config)
+}
+
+func formatLLCppgConfig(config LLCppgConfig) ([]byte, error) {
+	formatted := formattedLLCppgConfig{
+		Name:         config.Name,
+		CFlags:       config.CFlags,
+		Libs:         config.Libs,
+		Include:      config.Include,
+		Deps:         config.Deps,
+		TrimPrefixes: config.TrimPrefixes,
+		Cplusplus:    config.Cplusplus,
+	}
+	if len(config.Extra) == 0 {
+		return encodeCanonical(&formatted)
+	}
+
+	// append the extra fields to the object, and indent it as a whole
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(&formatted); err != nil {
+		return nil, fmt.Errorf("failed to encode config file: %w", err)
+	}
+	object := bytes.TrimSuffix(buf.Bytes(), []byte("}\n"))
+	keys := make([]string, 0, len(config.Extra))
+	for key := range config.Extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		name, _ := json.Marshal(key)
+		object = append(object, ',')
+		object = append(object, name...)
+		object = append(object, ':')
+		object = append(object, config.Extra[key]...)
+	}
+	object = append(object, '}')
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, object, "", "  "); err != nil {
+		return nil, fmt.Errorf("failed to encode config file: %w", err)
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// decodeStrict decodes a single JSON object from src, rejecting unknown fields
+// because formatting would drop them otherwise.
+func decodeStrict(src []byte, v any) error {
+	return decode(src, v, true)
+}
+
+// decode decodes a single JSON object from src.
+func decode(src []byte, v any, disallowUnknownFields bool) error {
+	decoder := json.NewDecoder(bytes.NewReader(src))
+	if disallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("failed to decode config file: %w", err)
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to decode config file: unexpected data after the config")
+	}
+	return nil
+}
+
+func encodeCanonical(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return nil, fmt.Errorf("failed to encode config file: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"os"
+	"testing"
+)
+
+func TestFormatLLPkgConfig(t *testing.T) {
+	src := `{
+    "upstream": {
+        "package": {"version": "1.7.18", "name": "cjson"},
+        "installer": {"config": {"options": ""}, "name": "conan"}
+    }
+}`
+	expected := `{
+  "upstream": {
+    "package": {
+      "name": "cjson",
+      "version": "1.7.18"
+    }
+  }
+}
+`
+	formatted, err := Format("llpkg.cfg", []byte(src))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if string(formatted) != expected {
+		t.Errorf("unexpected result: got: %s", formatted)
+	}
+
+	// formatting is idempotent
+	again, err := Format("llpkg.cfg", formatted)
+	if err != nil || string(again) != expected {
+		t.Errorf("unexpected result: got: %s %v", again, err)
+	}
+
+	t.Run("installer-options", func(t *testing.T) {
+		src := `{"upstream":{"installer":{"name":"conan","config":{"options":"cjson/*:utils=True"}},"package":{"name":"cjson","version":"1.7.18"}}}`
+		expected := `{
+  "upstream": {
+    "installer": {
+      "config": {
+        "options": "cjson/*:utils=True"
+      }
+    },
+    "package": {
+      "name": "cjson",
+      "version": "1.7.18"
+    }
+  }
+}
+`
+		formatted, err := Format("llpkg.cfg", []byte(src))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if string(formatted) != expected {
+			t.Errorf("unexpected result: got: %s", formatted)
+		}
+	})
+
+	t.Run("unknown-field", func(t *testing.T) {
+		src := `{"upstream":{"package":{"name":"cjson","version":"1.7.18"}},"unknown":true}`
+		if _, err := Format("llpkg.cfg", []byte(src)); err == nil {
+			t.Error("unexpected behavior: unknown field is dropped")
+		}
+	})
+}
+
+func TestFormatLLCppgConfig(t *testing.T) {
+	src, err := os.ReadFile("../_demo/llcppg.cfg")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	expected := `{
+  "name": "cjson",
+  "cflags": "$(pkg-config --cflags cjson)",
+  "libs": "$(pkg-config --libs cjson)",
+  "include": [
+    "cjson/cJSON.h"
+  ]
+}
+`
+	formatted, err := Format("llcppg.cfg", src)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if string(formatted) != expected {
+		t.Errorf("unexpected result: got: %s", formatted)
+	}
+
+	if _, err := Format("llcppg.symb.json", src); err == nil {
+		t.Error("unexpected behavior: unknown config file is formatted")
+	}
+
+	t.Run("extra-fields", func(t *testing.T) {
+		src := `{"symMap":{"zlibVersion":"Version"},"staticLib":true,"name":"zlib","cflags":"","libs":"",` +
+			`"include":["zlib.h"],"typeMap":{"gzFile":"GzFile"},"headerOnly":false}`
+		expected := `{
+  "name": "zlib",
+  "cflags": "",
+  "libs": "",
+  "include": [
+    "zlib.h"
+  ],
+  "headerOnly": false,
+  "staticLib": true,
+  "symMap": {
+    "zlibVersion": "Version"
+  },
+  "typeMap": {
+    "gzFile": "GzFile"
+  }
+}
+`
+		formatted, err := Format("llcppg.cfg", []byte(src))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if string(formatted) != expected {
+			t.Errorf("unexpected result: got: %s", formatted)
+		}
+		again, err := Format("llcppg.cfg", formatted)
+		if err != nil || string(again) != expected {
+			t.Errorf("unexpected behavior: canonical form is changed by Format: %s %v", again, err)
+		}
+	})
+}
+
+func TestFormatLLPkgConfigDependencies(t *testing.T) {
+	src := `{"dependencies":[{"version":"v1.0.0","name":"zlib"}],"upstream":{"package":{"name":"libxml2","version":"2.13.4"}}}`
+	expected := `{
+  "upstream": {
+    "package": {
+      "name": "libxml2",
+      "version": "2.13.4"
+    }
+  },
+  "dependencies": [
+    {
+      "name": "zlib",
+      "version": "v1.0.0"
+    }
+  ]
+}
+`
+	formatted, err := Format("llpkg.cfg", []byte(src))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if string(formatted) != expected {
+		t.Errorf("unexpected result: got: %s", formatted)
+	}
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// pkgConfigMatch matches pkg-config invocations like `$(pkg-config --cflags cjson)`
+var pkgConfigMatch = regexp.MustCompile(`\$\(\s*pkg-config\s+([^)]*)\)`)
+
+// LLCppgConfig represents the configuration structure parsed from llcppg.cfg files.
+type LLCppgConfig struct {
+	Name         string   `json:"name"`
+	CFlags       string   `json:"cflags"`
+	Libs         string   `json:"libs"`
+	Include      []string `json:"include"`
+	Deps         []string `json:"deps"`
+	TrimPrefixes []string `json:"trimPrefixes"`
+	Cplusplus    bool     `json:"cplusplus"`
+
+	// Extra holds the fields which aren't modeled above, like typeMap and symMap,
+	// so that they are kept when the config is formatted or written back.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// llcppgFields are the JSON names of the modeled fields of LLCppgConfig.
+var llcppgFields = []string{"name", "cflags", "libs", "include", "deps", "trimPrefixes", "cplusplus"}
+
+// UnmarshalJSON decodes the modeled fields, and keeps the others in Extra compacted.
+func (c *LLCppgConfig) UnmarshalJSON(b []byte) error {
+	type plain LLCppgConfig
+	if err := json.Unmarshal(b, (*plain)(c)); err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+	c.Extra = nil
+	for key, value := range fields {
+		// encoding/json matches the field names case-insensitively
+		if slices.ContainsFunc(llcppgFields, func(name string) bool { return strings.EqualFold(name, key) }) {
+			continue
+		}
+		var compacted bytes.Buffer
+		if err := json.Compact(&compacted, value); err != nil {
+			return err
+		}
+		if c.Extra == nil {
+			c.Extra = map[string]json.RawMessage{}
+		}
+		c.Extra[key] = compacted.Bytes()
+	}
+	return nil
+}
+
+// PkgConfigNames returns the pkg-config names referenced by cflags and libs,
+// in order of appearance and without duplicates.
+//
+// Example: "$(pkg-config --cflags cjson libcjson)" => [cjson libcjson]
+func (c LLCppgConfig) PkgConfigNames() (names []string) {
+	for _, flags := range []string{c.CFlags, c.Libs} {
+		for _, match := range pkgConfigMatch.FindAllStringSubmatch(flags, -1) {
+			for _, field := range strings.Fields(match[1]) {
+				// skip pkg-config options, like --cflags and --libs
+				if strings.HasPrefix(field, "-") || slices.Contains(names, field) {
+					continue
+				}
+				names = append(names, field)
+			}
+		}
+	}
+	return
+}
+
+// ParseLLCppgConfig reads and parses the llcppg.cfg configuration file.
+func ParseLLCppgConfig(configPath string) (LLCppgConfig, error) {
+	var config LLCppgConfig
+	file, err := os.Open(configPath)
+	if err != nil {
+		return config, fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	err = decoder.Decode(&config)
+	if err != nil {
+		return config, fmt.Errorf("failed to decode config file: %w", err)
+	}
+	return config, nil
+}
+
+// WriteLLCppgConfig serializes the configuration to configPath in the canonical form,
+// so that the written file is left unchanged by Format.
+func WriteLLCppgConfig(configPath string, config LLCppgConfig) error {
+	b, err := formatLLCppgConfig(config)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(configPath, b, 0644)
+}
+
+// ValidateLLCppgConfig validates llcppg.cfg against its llpkg.cfg and the output
+// of upstream.Installer.Install in installDir.
+//
+// It checks:
+//
+// 1. name equals to upstream.package.name in llpkg.cfg.
+// 2. every include header exists in the installed include directory.
+// 3. every pkg-config name in cflags and libs has an installed .pc file.
+func ValidateLLCppgConfig(config LLCppgConfig, pkgConfig LLPkgConfig, installDir string) error {
+	if config.Name != pkgConfig.Upstream.Package.Name {
+		return fmt.Errorf("mismatched package name: llcppg.cfg name %q is not equal to upstream.package.name %q",
+			config.Name, pkgConfig.Upstream.Package.Name)
+	}
+	if len(config.Include) == 0 {
+		return fmt.Errorf("missing required headers: include cannot be empty")
+	}
+
+	includeDir := filepath.Join(installDir, "include")
+	for _, header := range config.Include {
+		if !hasHeader(includeDir, header) {
+			return fmt.Errorf("header not found: %s doesn't exist in %s", header, includeDir)
+		}
+	}
+
+	for _, name := range config.PkgConfigNames() {
+		if _, err := os.Stat(filepath.Join(installDir, name+".pc")); err != nil {
+			return fmt.Errorf("pkg-config file not found: %s.pc isn't installed", name)
+		}
+	}
+	return nil
+}
+
+// hasHeader reports whether header exists in includeDir.
+// Headers may also be located in a subdirectory added by cflags,
+// for example, libxml/parser.h is installed to include/libxml2/libxml/parser.h.
+func hasHeader(includeDir, header string) bool {
+	if _, err := os.Stat(filepath.Join(includeDir, header)); err == nil {
+		return true
+	}
+	suffix := string(filepath.Separator) + filepath.Clean(header)
+	found := false
+	filepath.WalkDir(includeDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !d.IsDir() && strings.HasSuffix(path, suffix) {
+			found = true
+			return fs.SkipAll
+		}
+		return nil
+	})
+	return found
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseLLCppgConfig(t *testing.T) {
+	cfg, err := ParseLLCppgConfig("../_demo/llcppg.cfg")
+	if err != nil {
+		t.Errorf("Error parsing config file: %v", err)
+		return
+	}
+	expected := LLCppgConfig{
+		Name:         "cjson",
+		CFlags:       "$(pkg-config --cflags cjson)",
+		Libs:         "$(pkg-config --libs cjson)",
+		Include:      []string{"cjson/cJSON.h"},
+		TrimPrefixes: []string{},
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("Unexpected config: want: %v got: %v", expected, cfg)
+	}
+	if names := cfg.PkgConfigNames(); !reflect.DeepEqual(names, []string{"cjson"}) {
+		t.Errorf("Unexpected pkg-config names: %v", names)
+	}
+}
+
+func TestWriteLLCppgConfig(t *testing.T) {
+	cfg, err := ParseLLCppgConfig("../_demo/llcppg.cfg")
+	if err != nil {
+		t.Errorf("Error parsing config file: %v", err)
+		return
+	}
+	path := filepath.Join(t.TempDir(), "llcppg.cfg")
+	if err := WriteLLCppgConfig(path, cfg); err != nil {
+		t.Errorf("Error writing config file: %v", err)
+		return
+	}
+	written, err := ParseLLCppgConfig(path)
+	if err != nil {
+		t.Errorf("Error parsing written config file: %v", err)
+		return
+	}
+	// empty fields are omitted in the canonical form
+	cfg.TrimPrefixes = nil
+	if !reflect.DeepEqual(cfg, written) {
+		t.Errorf("Unexpected config: want: %v got: %v", cfg, written)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	formatted, err := Format(path, b)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if string(formatted) != string(b) {
+		t.Errorf("unexpected behavior: written config is changed by Format: got: %s want: %s", formatted, b)
+	}
+}
+
+func TestLLCppgConfigExtra(t *testing.T) {
+	src := `{"name":"zlib","cflags":"$(pkg-config --cflags zlib)","libs":"$(pkg-config --libs zlib)",` +
+		`"include":["zlib.h"],"typeMap":{"gzFile":"GzFile"},"symMap":{"zlibVersion":"Version"},"staticLib":true,"headerOnly":false}`
+	path := filepath.Join(t.TempDir(), "llcppg.cfg")
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Error(err)
+		return
+	}
+	cfg, err := ParseLLCppgConfig(path)
+	if err != nil {
+		t.Errorf("Error parsing config file: %v", err)
+		return
+	}
+	if len(cfg.Extra) != 4 || string(cfg.Extra["staticLib"]) != "true" {
+		t.Errorf("Unexpected extra fields: %v", cfg.Extra)
+	}
+
+	if err := WriteLLCppgConfig(path, cfg); err != nil {
+		t.Errorf("Error writing config file: %v", err)
+		return
+	}
+	written, err := ParseLLCppgConfig(path)
+	if err != nil {
+		t.Errorf("Error parsing written config file: %v", err)
+		return
+	}
+	if !reflect.DeepEqual(cfg, written) {
+		t.Errorf("Unexpected config: want: %v got: %v", cfg, written)
+	}
+}
+
+func TestPkgConfigNames(t *testing.T) {
+	cfg := LLCppgConfig{
+		CFlags: "$(pkg-config --cflags libxml-2.0 zlib)",
+		Libs:   "$(pkg-config --libs libxml-2.0) -lm",
+	}
+	if names := cfg.PkgConfigNames(); !reflect.DeepEqual(names, []string{"libxml-2.0", "zlib"}) {
+		t.Errorf("Unexpected pkg-config names: %v", names)
+	}
+}
+
+func TestValidateLLCppgConfig(t *testing.T) {
+	pkgCfg, err := ParseLLPkgConfig("../_demo/llpkg.cfg")
+	if err != nil {
+		t.Errorf("Error parsing config file: %v", err)
+		return
+	}
+	cfg, err := ParseLLCppgConfig("../_demo/llcppg.cfg")
+	if err != nil {
+		t.Errorf("Error parsing config file: %v", err)
+		return
+	}
+
+	// mock the output of installer
+	installDir := t.TempDir()
+	os.MkdirAll(filepath.Join(installDir, "include", "cjson"), 0777)
+	os.WriteFile(filepath.Join(installDir, "include", "cjson", "cJSON.h"), nil, 0644)
+	os.WriteFile(filepath.Join(installDir, "cjson.pc"), nil, 0644)
+
+	if err := ValidateLLCppgConfig(cfg, pkgCfg, installDir); err != nil {
+		t.Errorf("Error validating config: %v", err)
+	}
+
+	t.Run("nested-header", func(t *testing.T) {
+		cfg := cfg
+		cfg.Include = []string{"cJSON.h"}
+		if err := ValidateLLCppgConfig(cfg, pkgCfg, installDir); err != nil {
+			t.Errorf("Error validating config: %v", err)
+		}
+	})
+
+	t.Run("missing-header", func(t *testing.T) {
+		cfg := cfg
+		cfg.Include = []string{"cjson/cJSON_Utils.h"}
+		if err := ValidateLLCppgConfig(cfg, pkgCfg, installDir); err == nil {
+			t.Error("unexpected behavior: missing header is accepted")
+		}
+	})
+
+	t.Run("mismatched-name", func(t *testing.T) {
+		cfg := cfg
+		cfg.Name = "libcjson"
+		if err := ValidateLLCppgConfig(cfg, pkgCfg, installDir); err == nil {
+			t.Error("unexpected behavior: mismatched name is accepted")
+		}
+	})
+
+	t.Run("missing-pc", func(t *testing.T) {
+		cfg := cfg
+		cfg.Libs = "$(pkg-config --libs cjson libcjson_utils)"
+		if err := ValidateLLCppgConfig(cfg, pkgCfg, installDir); err == nil {
+			t.Error("unexpected behavior: missing pc file is accepted")
+		}
+	})
+}
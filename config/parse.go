@@ -33,6 +33,19 @@ func ParseLLPkgConfig(configPath string) (LLPkgConfig, error) {
 	return config, nil
 }
 
+// WriteLLPkgConfig serializes the configuration to configPath in canonical form.
+func WriteLLPkgConfig(configPath string, config LLPkgConfig) error {
+	b, err := json.Marshal(&config)
+	if err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+	b, err = FormatLLPkgConfig(b)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(configPath, b, 0644)
+}
+
 // fillDefaults applies default configuration values when parameters are missing.
 // Current defaults:
 // - installer.name: Uses first valid installer type if unspecified.
@@ -2,6 +2,8 @@ package config
 
 import (
 	"encoding/json"
+	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -20,3 +22,21 @@ func TestParseLLPkgConfig(t *testing.T) {
 		t.Errorf("Unexpected config: %s", string(json))
 	}
 }
+
+func TestWriteLLPkgConfig(t *testing.T) {
+	config, err := ParseLLPkgConfig("../_demo/llpkg.cfg")
+	if err != nil {
+		t.Errorf("Error parsing config file: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "llpkg.cfg")
+	if err := WriteLLPkgConfig(path, config); err != nil {
+		t.Errorf("Error writing config file: %v", err)
+	}
+	written, err := ParseLLPkgConfig(path)
+	if err != nil {
+		t.Errorf("Error parsing written config file: %v", err)
+	}
+	if !reflect.DeepEqual(config, written) {
+		t.Errorf("Unexpected config: want: %v got: %v", config, written)
+	}
+}
@@ -2,13 +2,74 @@ package config
 
 import (
 	"fmt"
+	"net/url"
+	"regexp"
 	"slices"
+	"strings"
+
+	"github.com/goplus/llpkgstore/upstream"
+	"golang.org/x/mod/semver"
+)
+
+const maxDescriptionLength = 256
+
+var (
+	// licenseMatch matches SPDX license identifiers and expressions, e.g. "MIT", "Apache-2.0 OR MIT"
+	licenseMatch = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.+\-]*( (AND|OR|WITH) [A-Za-z0-9][A-Za-z0-9.+\-]*)*$`)
+	// tagMatch matches lowercase tags joined by hyphens, e.g. "json", "image-processing"
+	tagMatch = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
 )
 
 // ValidateLLPkgConfig performs structural validation of the configuration.
-// Validates upstream installer and package metadata requirements.
+// Validates descriptive fields, upstream installer, package metadata and dependencies requirements.
 func ValidateLLPkgConfig(config LLPkgConfig) error {
-	return validateUpstreamConfig(config.Upstream)
+	if err := validateDescriptiveConfig(config); err != nil {
+		return err
+	}
+	if err := validateUpstreamConfig(config.Upstream); err != nil {
+		return err
+	}
+	return validateDependencyConfig(config.Dependencies, config.Upstream.Package.Name)
+}
+
+// validateDescriptiveConfig performs validation of the optional descriptive fields.
+func validateDescriptiveConfig(config LLPkgConfig) error {
+	if strings.ContainsAny(config.Description, "\r\n") {
+		return fmt.Errorf("invalid description: description must be a single line")
+	}
+	if len(config.Description) > maxDescriptionLength {
+		return fmt.Errorf("invalid description: description cannot exceed %d characters", maxDescriptionLength)
+	}
+
+	if config.Homepage != "" {
+		u, err := url.Parse(config.Homepage)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid homepage: %s is not a http(s) URL", config.Homepage)
+		}
+	}
+
+	if config.License != "" && !licenseMatch.MatchString(config.License) {
+		return fmt.Errorf("invalid license: %s is not a SPDX license expression", config.License)
+	}
+
+	for i, maintainer := range config.Maintainers {
+		if strings.TrimSpace(maintainer) == "" {
+			return fmt.Errorf("invalid maintainer: maintainers cannot contain empty item")
+		}
+		if slices.Contains(config.Maintainers[:i], maintainer) {
+			return fmt.Errorf("duplicate maintainer: %s", maintainer)
+		}
+	}
+
+	for i, tag := range config.Tags {
+		if !tagMatch.MatchString(tag) {
+			return fmt.Errorf("invalid tag: %s, tags must be lowercase words joined by hyphens", tag)
+		}
+		if slices.Contains(config.Tags[:i], tag) {
+			return fmt.Errorf("duplicate tag: %s", tag)
+		}
+	}
+	return nil
 }
 
 // validateUpstreamConfig performs detailed validation of upstream configuration parameters.
@@ -31,3 +92,64 @@ func validateUpstreamConfig(config UpstreamConfig) error {
 
 	return nil
 }
+
+// validateDependencyConfig performs detailed validation of declared llpkg dependencies.
+func validateDependencyConfig(dependencies []DependencyConfig, packageName string) error {
+	seen := make(map[string]struct{}, len(dependencies))
+	for _, dep := range dependencies {
+		if dep.Name == "" {
+			return fmt.Errorf("missing required dependency name: dependencies.name cannot be empty")
+		}
+		if dep.Name == packageName {
+			return fmt.Errorf("invalid dependency: %s cannot depend on itself", dep.Name)
+		}
+		if _, ok := seen[dep.Name]; ok {
+			return fmt.Errorf("duplicate dependency: %s", dep.Name)
+		}
+		seen[dep.Name] = struct{}{}
+
+		// go.mod can only require a minimal version, which is selected by MVS
+		if strings.ContainsAny(dep.Version, "<>=!, ") {
+			return fmt.Errorf("invalid dependency version: %s@%s is a constraint, only the minimal mapped version is accepted", dep.Name, dep.Version)
+		}
+		if !semver.IsValid(dep.Version) {
+			return fmt.Errorf("invalid dependency version: %s@%s is not a mapped version", dep.Name, dep.Version)
+		}
+	}
+	return nil
+}
+
+// ValidateDependencies checks the declared llpkg dependencies against the runtime
+// C dependencies of upstream, which are retrieved by upstream.Installer.Dependency.
+//
+// Every C dependency must have a declared llpkg counterpart, and vice versa.
+func ValidateDependencies(config LLPkgConfig, dependencies []upstream.Package) error {
+	declared := make(map[string]struct{}, len(config.Dependencies))
+	for _, dep := range config.Dependencies {
+		declared[dep.Name] = struct{}{}
+	}
+
+	var undeclared []string
+	for _, dep := range dependencies {
+		if _, ok := declared[dep.Name]; !ok {
+			undeclared = append(undeclared, dep.Name+"/"+dep.Version)
+			continue
+		}
+		delete(declared, dep.Name)
+	}
+	if len(undeclared) > 0 {
+		slices.Sort(undeclared)
+		return fmt.Errorf("undeclared dependencies: %v should be declared in dependencies", undeclared)
+	}
+
+	if len(declared) > 0 {
+		var unused []string
+		for name := range declared {
+			unused = append(unused, name)
+		}
+		slices.Sort(unused)
+		return fmt.Errorf("unused dependencies: %v are not required by upstream %s",
+			unused, config.Upstream.Package.Name)
+	}
+	return nil
+}
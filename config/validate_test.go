@@ -1,6 +1,10 @@
 package config
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/goplus/llpkgstore/upstream"
+)
 
 func TestValidateLLPkgConfig(t *testing.T) {
 	config, err := ParseLLPkgConfig("../_demo/llpkg.cfg")
@@ -12,3 +16,83 @@ func TestValidateLLPkgConfig(t *testing.T) {
 		t.Errorf("Error validating config: %v", err)
 	}
 }
+
+func TestValidateDependencyConfig(t *testing.T) {
+	config, err := ParseLLPkgConfig("../_demo/llpkg.cfg")
+	if err != nil {
+		t.Errorf("Error parsing config file: %v", err)
+	}
+	config.Dependencies = []DependencyConfig{{Name: "zlib", Version: "v1.0.0"}}
+	if err := ValidateLLPkgConfig(config); err != nil {
+		t.Errorf("Error validating config: %v", err)
+	}
+
+	for name, deps := range map[string][]DependencyConfig{
+		"empty-name":      {{Version: "v1.0.0"}},
+		"self":            {{Name: "cjson", Version: "v1.0.0"}},
+		"duplicate":       {{Name: "zlib", Version: "v1.0.0"}, {Name: "zlib", Version: "v1.1.0"}},
+		"invalid-version": {{Name: "zlib", Version: "1.3.1"}},
+		"constraint":      {{Name: "zlib", Version: ">=v1.0.0, <v2.0.0"}},
+	} {
+		config.Dependencies = deps
+		if err := ValidateLLPkgConfig(config); err == nil {
+			t.Errorf("unexpected behavior: %s is accepted", name)
+		}
+	}
+}
+
+func TestValidateDependencies(t *testing.T) {
+	config := LLPkgConfig{
+		Upstream: UpstreamConfig{
+			Package: PackageConfig{Name: "libxslt", Version: "1.1.42"},
+		},
+		Dependencies: []DependencyConfig{
+			{Name: "libxml2", Version: "v1.0.0"},
+			{Name: "zlib", Version: "v1.0.0"},
+		},
+	}
+	deps := []upstream.Package{
+		{Name: "libxml2", Version: "2.13.4"},
+		{Name: "zlib", Version: "1.3.1"},
+	}
+	if err := ValidateDependencies(config, deps); err != nil {
+		t.Errorf("Error validating dependencies: %v", err)
+	}
+	if err := ValidateDependencies(config, deps[:1]); err == nil {
+		t.Error("unexpected behavior: unused dependency is accepted")
+	}
+	deps = append(deps, upstream.Package{Name: "libiconv", Version: "1.17"})
+	if err := ValidateDependencies(config, deps); err == nil {
+		t.Error("unexpected behavior: undeclared dependency is accepted")
+	}
+}
+
+func TestValidateDescriptiveConfig(t *testing.T) {
+	config, err := ParseLLPkgConfig("../_demo/llpkg.cfg")
+	if err != nil {
+		t.Errorf("Error parsing config file: %v", err)
+	}
+	config.Description = "Ultralightweight JSON parser in ANSI C"
+	config.Homepage = "https://github.com/DaveGamble/cJSON"
+	config.License = "MIT"
+	config.Maintainers = []string{"goplus"}
+	config.Tags = []string{"json", "parser"}
+	if err := ValidateLLPkgConfig(config); err != nil {
+		t.Errorf("Error validating config: %v", err)
+	}
+
+	for name, modify := range map[string]func(*LLPkgConfig){
+		"multiline-description": func(c *LLPkgConfig) { c.Description = "JSON\nparser" },
+		"relative-homepage":     func(c *LLPkgConfig) { c.Homepage = "github.com/DaveGamble/cJSON" },
+		"invalid-license":       func(c *LLPkgConfig) { c.License = "MIT license!" },
+		"empty-maintainer":      func(c *LLPkgConfig) { c.Maintainers = []string{" "} },
+		"duplicate-tag":         func(c *LLPkgConfig) { c.Tags = []string{"json", "json"} },
+		"invalid-tag":           func(c *LLPkgConfig) { c.Tags = []string{"JSON Parser"} },
+	} {
+		c := config
+		modify(&c)
+		if err := ValidateLLPkgConfig(c); err == nil {
+			t.Errorf("unexpected behavior: %s is accepted", name)
+		}
+	}
+}
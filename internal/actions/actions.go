@@ -96,6 +96,12 @@ func shaFromTag(tag string) string {
 	return strings.TrimSpace(string(ret))
 }
 
+// ParseMappedVersion splits the mapped version like cjson/v1.0.0
+// into the C library name and the mapped version.
+func ParseMappedVersion(version string) (clib, mappedVersion string, err error) {
+	return parseMappedVersion(version)
+}
+
 // parseMappedVersion splits the mapped version string into library name and version.
 // Input format: "clib/semver" where semver starts with 'v'
 // Panics if input format is invalid or version isn't valid semantic version
@@ -3,21 +3,29 @@ package actions
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"mime"
 	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/go-github/v69/github"
 	"github.com/goplus/llpkgstore/config"
 	"github.com/goplus/llpkgstore/internal/actions/env"
 	"github.com/goplus/llpkgstore/internal/actions/versions"
+	"github.com/goplus/llpkgstore/metadata"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -48,6 +56,17 @@ func binaryZip(packageName string) string {
 	return fmt.Sprintf("%s_%s.zip", packageName, currentSuffix)
 }
 
+// binaryZipPlatform returns the platform GOOS/GOARCH of the binary zip made by binaryZip,
+// or empty if fileName isn't such one.
+func binaryZipPlatform(packageName, fileName string) string {
+	suffix := strings.TrimPrefix(strings.TrimSuffix(fileName, ".zip"), packageName+"_")
+	goos, goarch, ok := strings.Cut(suffix, "_")
+	if !ok || suffix+".zip" == fileName {
+		return ""
+	}
+	return goos + "/" + goarch
+}
+
 // DefaultClient provides GitHub API client capabilities with authentication for Actions workflows
 type DefaultClient struct {
 	// repo: Target repository name
@@ -404,7 +423,7 @@ func (d *DefaultClient) uploadToRelease(
 	size int64,
 	reader io.Reader,
 	release *github.RepositoryRelease,
-) error {
+) (*github.ReleaseAsset, error) {
 	ctx, cancel := context.WithTimeout(context.TODO(), 30*time.Second)
 	defer cancel()
 
@@ -412,18 +431,19 @@ func (d *DefaultClient) uploadToRelease(
 
 	req, err := d.client.NewUploadRequest(url, reader, size, "application/zip")
 	if err != nil {
-		return wrapActionError(err)
+		return nil, wrapActionError(err)
 	}
 
 	asset := new(github.ReleaseAsset)
 	_, err = d.client.Do(ctx, req, asset)
 	if err != nil {
-		return wrapActionError(err)
+		return nil, wrapActionError(err)
 	}
-	return nil
+	return asset, nil
 }
 
-func (d *DefaultClient) uploadArtifact(artifactID int64, release *github.RepositoryRelease) error {
+// uploadArtifact uploads the artifact to the release, and returns the details of the uploaded asset.
+func (d *DefaultClient) uploadArtifact(clib string, artifactID int64, release *github.RepositoryRelease) (metadata.Asset, error) {
 	ctx, cancel := context.WithTimeout(context.TODO(), 30*time.Second)
 	defer cancel()
 
@@ -431,34 +451,48 @@ func (d *DefaultClient) uploadArtifact(artifactID int64, release *github.Reposit
 		artifactID, 0)
 
 	if err != nil {
-		return wrapActionError(err)
+		return metadata.Asset{}, wrapActionError(err)
 	}
 
 	httpClient := &http.Client{Timeout: 30 * time.Second}
 
 	resp, err := httpClient.Get(url.String())
 	if err != nil {
-		return wrapActionError(err)
+		return metadata.Asset{}, wrapActionError(err)
 	}
 	defer resp.Body.Close()
 
 	disposition := resp.Header.Get("Content-Disposition")
 	_, params, err := mime.ParseMediaType(disposition)
 	if err != nil {
-		return wrapActionError(err)
+		return metadata.Asset{}, wrapActionError(err)
 	}
 
 	fileName, ok := params["filename"]
 	if !ok {
-		return errors.New("actions: no filename found in Content-Disposition")
+		return metadata.Asset{}, errors.New("actions: no filename found in Content-Disposition")
 	}
 
 	fmt.Printf("Upload %s to %s\n", fileName, release.GetName())
 
-	return d.uploadToRelease(fileName, resp.ContentLength, resp.Body, release)
+	// compute the checksum while uploading
+	hash := sha256.New()
+	asset, err := d.uploadToRelease(fileName, resp.ContentLength, io.TeeReader(resp.Body, hash), release)
+	if err != nil {
+		return metadata.Asset{}, err
+	}
+
+	return metadata.Asset{
+		Name:     fileName,
+		Platform: binaryZipPlatform(clib, fileName),
+		URL:      asset.GetBrowserDownloadURL(),
+		SHA256:   hex.EncodeToString(hash.Sum(nil)),
+	}, nil
 }
 
-func (d *DefaultClient) uploadArtifactsToRelease(release *github.RepositoryRelease) (files []*os.File, err error) {
+// uploadArtifactsToRelease uploads the artifacts of current workflow run to the release,
+// and returns the details of the uploaded assets ordered by name.
+func (d *DefaultClient) uploadArtifactsToRelease(clib string, release *github.RepositoryRelease) (assets []metadata.Asset, err error) {
 	ctx, cancel := context.WithTimeout(context.TODO(), 30*time.Second)
 	defer cancel()
 
@@ -475,17 +509,29 @@ func (d *DefaultClient) uploadArtifactsToRelease(release *github.RepositoryRelea
 	}
 
 	errGroup, _ := errgroup.WithContext(context.TODO())
+	var mu sync.Mutex
 
 	for _, artifact := range artifacts.Artifacts {
 		// make a copy to avoid for loop bug
 		artifactID := artifact.GetID()
 
 		errGroup.Go(func() error {
-			return d.uploadArtifact(artifactID, release)
+			asset, err := d.uploadArtifact(clib, artifactID, release)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			assets = append(assets, asset)
+			mu.Unlock()
+			return nil
 		})
 	}
 
-	return nil, errGroup.Wait()
+	if err := errGroup.Wait(); err != nil {
+		return nil, err
+	}
+	sort.Slice(assets, func(i, j int) bool { return assets[i].Name < assets[j].Name })
+	return assets, nil
 }
 
 // removeBranch deletes a branch from the repository
@@ -548,7 +594,10 @@ func (d *DefaultClient) CheckPR() ([]string, error) {
 
 	var allPaths []string
 
-	ver := versions.Read("llpkgstore.json")
+	ver, err := versions.Read("llpkgstore.json")
+	if err != nil {
+		return nil, wrapActionError(err)
+	}
 
 	for path := range pathMap {
 		// don't retrieve files from pr changes, consider about maintenance case
@@ -601,6 +650,18 @@ func (d *DefaultClient) CheckPR() ([]string, error) {
 // Postprocessing handles version tagging and record updates after PR merge
 // Creates Git tags, updates version records, and cleans up legacy branches
 func (d *DefaultClient) Postprocessing() error {
+	// parse the signing key before publishing anything, so that a malformed key
+	// never leaves a published llpkgstore.json without a valid signature.
+	var signingKey ed25519.PrivateKey
+	if encodedKey, err := env.SigningKey(); err == nil {
+		signingKey, err = metadata.ParsePrivateKey(encodedKey)
+		if err != nil {
+			return wrapActionError(err)
+		}
+	} else {
+		log.Printf("actions: skip signing llpkgstore.json: %v", err)
+	}
+
 	// https://docs.github.com/en/actions/writing-workflows/choosing-when-your-workflow-runs/events-that-trigger-workflows#push
 	sha, err := env.LatestCommitSHA()
 	if err != nil {
@@ -628,9 +689,21 @@ func (d *DefaultClient) Postprocessing() error {
 		return err
 	}
 
-	// write it to llpkgstore.json
-	ver := versions.Read("llpkgstore.json")
-	ver.Write(clib, cfg.Upstream.Package.Version, mappedVersion)
+	ver, err := versions.Read("llpkgstore.json")
+	if err != nil {
+		return wrapActionError(err)
+	}
+	// the format of the file is kept, unless it's switched explicitly
+	if formatName, err := env.MappingFormat(); err == nil {
+		format, err := metadata.ParseMappingFormat(formatName)
+		if err != nil {
+			return wrapActionError(err)
+		}
+		ver.SetFormat(format)
+	}
+	if slices.Contains(ver.GoVersions(clib), mappedVersion) {
+		return wrapActionError(fmt.Errorf("%s/%s: %w", clib, mappedVersion, versions.ErrDuplicateVersion))
+	}
 
 	if hasTag(version) {
 		return fmt.Errorf("actions: tag has already existed")
@@ -646,11 +719,40 @@ func (d *DefaultClient) Postprocessing() error {
 		return err
 	}
 
-	_, err = d.uploadArtifactsToRelease(release)
+	assets, err := d.uploadArtifactsToRelease(clib, release)
 	if err != nil {
 		return err
 	}
 
+	// write it to llpkgstore.json with the release details, after it's released,
+	// so that a failed release never leaves a mapped version without its tag.
+	publishedAt := release.GetPublishedAt().Time
+	if publishedAt.IsZero() {
+		publishedAt = time.Now()
+	}
+	ver.SetPackageInfo(clib, metadata.PackageInfo{
+		Description: cfg.Description,
+		Homepage:    cfg.Homepage,
+		License:     cfg.License,
+		Maintainers: cfg.Maintainers,
+		Tags:        cfg.Tags,
+	})
+	err = ver.WriteRelease(clib, cfg.Upstream.Package.Version, mappedVersion, metadata.Release{
+		Time:   publishedAt.UTC(),
+		Commit: sha,
+		Assets: assets,
+	})
+	if err != nil {
+		return wrapActionError(err)
+	}
+
+	// sign it, so that clients can verify it's published by us
+	if signingKey != nil {
+		if err := ver.Sign(signingKey); err != nil {
+			return wrapActionError(err)
+		}
+	}
+
 	// we have finished tagging the commit, safe to remove the branch
 	branchName, isLegacy, err := d.isLegacyVersion()
 	if err != nil {
@@ -726,7 +828,10 @@ func (d *DefaultClient) CreateBranchFromLabel(labelName string) error {
 	// according to branch maintenance strategy
 
 	// get latest version of the clib
-	ver := versions.Read("llpkgstore.json")
+	ver, err := versions.Read("llpkgstore.json")
+	if err != nil {
+		return wrapActionError(err)
+	}
 
 	cversions := ver.CVersions(clib)
 	if len(cversions) == 0 {
@@ -3,6 +3,7 @@ package actions
 import (
 	"os"
 	"os/exec"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -56,9 +57,12 @@ func TestLegacyVersion1(t *testing.T) {
 	defer os.Remove(".llpkgstore.json")
 
 	cfg, _ := config.ParseLLPkgConfig(".llpkg.cfg")
-	ver := versions.Read(".llpkgstore.json")
+	ver, err := versions.Read(".llpkgstore.json")
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	err := actionFn("main", func(legacy bool) error {
+	err = actionFn("main", func(legacy bool) error {
 		return checkLegacyVersion(ver, cfg, "v0.1.1", legacy)
 	})
 
@@ -86,7 +90,7 @@ func TestLegacyVersion2(t *testing.T) {
 			"versions" : {
 				"1.8.18": ["v0.2.0", "v0.2.1"],
 				"1.7.18": ["v0.1.0", "v0.1.1"],
-				"1.7.16: ["v1.1.0"]
+				"1.7.16": ["v1.1.0"]
 			}
 		}
 	}`)
@@ -95,9 +99,12 @@ func TestLegacyVersion2(t *testing.T) {
 	defer os.Remove(".llpkgstore.json")
 
 	cfg, _ := config.ParseLLPkgConfig(".llpkg.cfg")
-	ver := versions.Read(".llpkgstore.json")
+	ver, err := versions.Read(".llpkgstore.json")
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	err := actionFn("release-branch.cjson/v0.1.1", func(legacy bool) error {
+	err = actionFn("release-branch.cjson/v0.1.1", func(legacy bool) error {
 		return checkLegacyVersion(ver, cfg, "v0.1.2", legacy)
 	})
 	isValid := err == nil
@@ -134,9 +141,12 @@ func TestLegacyVersion3(t *testing.T) {
 	defer os.Remove(".llpkgstore.json")
 
 	cfg, _ := config.ParseLLPkgConfig(".llpkg.cfg")
-	ver := versions.Read(".llpkgstore.json")
+	ver, err := versions.Read(".llpkgstore.json")
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	err := actionFn("main", func(legacy bool) error {
+	err = actionFn("main", func(legacy bool) error {
 		return checkLegacyVersion(ver, cfg, "v0.3.0", legacy)
 	})
 	isValid := err == nil
@@ -173,9 +183,12 @@ func TestLegacyVersion4(t *testing.T) {
 	defer os.Remove(".llpkgstore.json")
 
 	cfg, _ := config.ParseLLPkgConfig(".llpkg.cfg")
-	ver := versions.Read(".llpkgstore.json")
+	ver, err := versions.Read(".llpkgstore.json")
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	err := actionFn("main", func(legacy bool) error {
+	err = actionFn("main", func(legacy bool) error {
 		return checkLegacyVersion(ver, cfg, "v0.0.1", legacy)
 	})
 
@@ -211,9 +224,12 @@ func TestLegacyVersion5(t *testing.T) {
 	defer os.Remove(".llpkgstore.json")
 
 	cfg, _ := config.ParseLLPkgConfig(".llpkg.cfg")
-	ver := versions.Read(".llpkgstore.json")
+	ver, err := versions.Read(".llpkgstore.json")
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	err := actionFn("main", func(legacy bool) error {
+	err = actionFn("main", func(legacy bool) error {
 		return checkLegacyVersion(ver, cfg, "v0.1.1", legacy)
 	})
 
@@ -222,3 +238,20 @@ func TestLegacyVersion5(t *testing.T) {
 		return
 	}
 }
+
+func TestBinaryZipPlatform(t *testing.T) {
+	tests := map[string]string{
+		"cjson_darwin_arm64.zip":  "darwin/arm64",
+		"cjson_linux_amd64.zip":   "linux/amd64",
+		"libxml2_linux_amd64.zip": "",
+		"cjson.zip":               "",
+	}
+	for fileName, expected := range tests {
+		if platform := binaryZipPlatform("cjson", fileName); platform != expected {
+			t.Errorf("%s: want %q got %q", fileName, expected, platform)
+		}
+	}
+	if platform := binaryZipPlatform("cjson", binaryZip("cjson")); platform != runtime.GOOS+"/"+runtime.GOARCH {
+		t.Errorf("unexpected platform: %s", platform)
+	}
+}
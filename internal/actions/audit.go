@@ -0,0 +1,177 @@
+package actions
+
+import (
+	"fmt"
+	"os/exec"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/goplus/llpkgstore/internal/actions/versions"
+	"github.com/goplus/llpkgstore/metadata"
+	"golang.org/x/mod/semver"
+)
+
+// Kinds of the issues found by Audit.
+const (
+	IssueInvalidVersion   = "invalid-version"   // mapped version isn't a canonical semantic version
+	IssueDuplicateVersion = "duplicate-version" // mapped version is used more than once
+	IssueVersionOrder     = "version-order"     // order of C versions and mapped versions disagree
+	IssueLegacyPatch      = "legacy-patch"      // patch maintenance on a superseded legacy patch line
+	IssueMissingTag       = "missing-tag"       // mapped version has no git tag
+)
+
+// AuditIssue is a violation of the invariants of llpkgstore.json.
+type AuditIssue struct {
+	Kind     string `json:"kind"`
+	Clib     string `json:"clib"`
+	CVersion string `json:"cversion,omitempty"`
+	Version  string `json:"version,omitempty"`
+	Message  string `json:"message"`
+}
+
+// AuditReport is the result of Audit.
+type AuditReport struct {
+	Packages    int          `json:"packages"`
+	Mappings    int          `json:"mappings"`
+	TagsChecked bool         `json:"tagsChecked"`
+	Issues      []AuditIssue `json:"issues"`
+}
+
+// OK reports whether no issues are found.
+func (r *AuditReport) OK() bool {
+	return len(r.Issues) == 0
+}
+
+func (r *AuditReport) report(kind, clib, cversion, version, format string, args ...any) {
+	r.Issues = append(r.Issues, AuditIssue{
+		Kind:     kind,
+		Clib:     clib,
+		CVersion: cversion,
+		Version:  version,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// TagLister lists all tags of the llpkg repository.
+type TagLister func() ([]string, error)
+
+// GitTags returns a TagLister listing the tags of the git repository in dir.
+func GitTags(dir string) TagLister {
+	return func() ([]string, error) {
+		ret, err := exec.Command("git", "-C", dir, "tag", "--list").CombinedOutput()
+		if err != nil {
+			return nil, fmt.Errorf("actions: cannot list tags: %w: %s", err, ret)
+		}
+		return strings.Fields(string(ret)), nil
+	}
+}
+
+// Audit verifies the invariants of the whole mapping table:
+//
+//   - mapped versions are canonical semantic versions, and unique per package.
+//   - the order of C versions agrees with the order of their mapped versions.
+//   - no patch maintenance on a superseded legacy patch line.
+//   - every mapped version has the clib/vX.Y.Z tag, unless listTags is nil.
+//
+// C versions which don't follow semver can't be ordered, the ordering checks are skipped for them.
+func Audit(m metadata.MetadataMap, listTags TagLister) (AuditReport, error) {
+	report := AuditReport{Issues: []AuditIssue{}}
+
+	var tags map[string]bool
+	if listTags != nil {
+		tagList, err := listTags()
+		if err != nil {
+			return report, err
+		}
+		tags = make(map[string]bool, len(tagList))
+		for _, tag := range tagList {
+			tags[tag] = true
+		}
+		report.TagsChecked = true
+	}
+
+	clibs := make([]string, 0, len(m))
+	for clib := range m {
+		clibs = append(clibs, clib)
+	}
+	sort.Strings(clibs)
+
+	for _, clib := range clibs {
+		data := m[clib]
+		if data == nil {
+			continue
+		}
+		report.Packages++
+
+		entries := data.Versions.Entries()
+		seen := make(map[string]string)
+		for _, entry := range entries {
+			for _, version := range entry.Go {
+				report.Mappings++
+				if semver.Canonical(version) != version {
+					report.report(IssueInvalidVersion, clib, entry.C, version,
+						"%s/%s isn't a canonical semantic version", clib, version)
+				}
+				if cversion, ok := seen[version]; ok && cversion == entry.C {
+					report.report(IssueDuplicateVersion, clib, entry.C, version,
+						"%s/%s is mapped to %s more than once", clib, version, entry.C)
+				} else if ok {
+					report.report(IssueDuplicateVersion, clib, entry.C, version,
+						"%s/%s is mapped to both %s and %s", clib, version, cversion, entry.C)
+				}
+				seen[version] = entry.C
+				if tags != nil && !tags[clib+"/"+version] {
+					report.report(IssueMissingTag, clib, entry.C, version,
+						"tag %s/%s doesn't exist", clib, version)
+				}
+			}
+		}
+		auditOrder(&report, clib, entries)
+	}
+	return report, nil
+}
+
+// auditOrder checks the mapped versions of each C version are all lower than
+// the ones of the next C version, which is enough for the whole table by transitivity.
+func auditOrder(report *AuditReport, clib string, entries []metadata.VersionEntry) {
+	type cRange struct {
+		cversion string // original C version
+		semver   string // C version in semver
+		min, max string // mapped versions
+	}
+	var ranges []cRange
+	for _, entry := range entries {
+		cversion := versions.ToSemVer(entry.C)
+		if !semver.IsValid(cversion) {
+			return
+		}
+		goVersions := slices.DeleteFunc(slices.Clone(entry.Go), func(v string) bool {
+			return !semver.IsValid(v)
+		})
+		if len(goVersions) == 0 {
+			continue
+		}
+		semver.Sort(goVersions)
+		ranges = append(ranges, cRange{entry.C, cversion, goVersions[0], goVersions[len(goVersions)-1]})
+	}
+	slices.SortFunc(ranges, func(a, b cRange) int {
+		return semver.Compare(a.semver, b.semver)
+	})
+
+	for i := 1; i < len(ranges); i++ {
+		lower, higher := ranges[i-1], ranges[i]
+		if semver.Compare(lower.max, higher.min) < 0 {
+			continue
+		}
+		if semver.MajorMinor(lower.semver) == semver.MajorMinor(higher.semver) {
+			report.report(IssueLegacyPatch, clib, lower.cversion, lower.max,
+				"%s@%s is maintained as %s after %s@%s was released as %s, only the newest patch version of a legacy line can be maintained",
+				clib, lower.cversion, lower.max, clib, higher.cversion, higher.min)
+			continue
+		}
+		report.report(IssueVersionOrder, clib, lower.cversion, lower.max,
+			"%s@%s is mapped to %s, which isn't lower than %s of the newer %s@%s",
+			clib, lower.cversion, lower.max, higher.min, clib, higher.cversion)
+	}
+}
@@ -0,0 +1,85 @@
+package actions
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/goplus/llpkgstore/metadata"
+)
+
+func TestAudit(t *testing.T) {
+	var m metadata.MetadataMap
+	err := json.Unmarshal([]byte(`{
+		"cjson": {"versions": {
+			"1.5.1": ["v1.0.0", "v1.0.1"],
+			"1.6": ["v1.1.0"],
+			"1.5.2": ["v1.0.2"]
+		}},
+		"legacy": {"versions": {
+			"1.5.1": ["v1.0.0", "v1.0.3"],
+			"1.5.2": ["v1.0.2"],
+			"1.6": ["v1.1.0"]
+		}},
+		"order": {"versions": {
+			"1.7.0": ["v1.2.0"],
+			"1.8.0": ["v1.1.0"]
+		}},
+		"dup": {"versions": {
+			"1.0.0": ["v1.0.0", "v1.0.0"],
+			"1.1.0": ["v1.0.0", "1.1"]
+		}},
+		"nonsemver": {"versions": {
+			"2024-01": ["v1.1.0"],
+			"2023-12": ["v1.0.0"]
+		}}
+	}`), &m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags := []string{
+		"cjson/v1.0.0", "cjson/v1.0.1", "cjson/v1.1.0", "cjson/v1.0.2",
+		"legacy/v1.0.0", "legacy/v1.0.3", "legacy/v1.0.2", "legacy/v1.1.0",
+		"order/v1.2.0", "order/v1.1.0",
+		"dup/v1.0.0",
+		"nonsemver/v1.1.0",
+	}
+
+	report, err := Audit(m, func() ([]string, error) { return tags, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.OK() || report.Packages != 5 || report.Mappings != 16 || !report.TagsChecked {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	type issue struct{ kind, clib, cversion, version string }
+	var got []issue
+	for _, i := range report.Issues {
+		got = append(got, issue{i.Kind, i.Clib, i.CVersion, i.Version})
+	}
+	expected := []issue{
+		{IssueDuplicateVersion, "dup", "1.0.0", "v1.0.0"},
+		{IssueDuplicateVersion, "dup", "1.1.0", "v1.0.0"},
+		{IssueInvalidVersion, "dup", "1.1.0", "1.1"},
+		{IssueMissingTag, "dup", "1.1.0", "1.1"},
+		{IssueVersionOrder, "dup", "1.0.0", "v1.0.0"},
+		{IssueLegacyPatch, "legacy", "1.5.1", "v1.0.3"},
+		{IssueMissingTag, "nonsemver", "2023-12", "v1.0.0"},
+		{IssueVersionOrder, "order", "1.7.0", "v1.2.0"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected issues:\nwant: %v\ngot:  %v", expected, got)
+	}
+
+	// tags aren't checked without a lister
+	report, err = Audit(metadata.MetadataMap{"cjson": m["cjson"]}, nil)
+	if err != nil || !report.OK() || report.TagsChecked {
+		t.Errorf("unexpected report: %+v %v", report, err)
+	}
+
+	if _, err := Audit(m, func() ([]string, error) { return nil, errors.New("no repo") }); err == nil {
+		t.Error("unexpected behavior: error of the tag lister is ignored")
+	}
+}
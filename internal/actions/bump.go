@@ -0,0 +1,160 @@
+package actions
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/goplus/llpkgstore/config"
+	"github.com/goplus/llpkgstore/internal/actions/versions"
+	"golang.org/x/mod/semver"
+)
+
+// Suggestion is the mapped version suggested for a submission of an llpkg.
+type Suggestion struct {
+	Clib          string
+	MappedVersion string
+	// Branch is the release branch the submission must target,
+	// it's empty for the main branch.
+	Branch string
+	// Reasons explains how the mapped version is chosen, step by step.
+	Reasons []string
+}
+
+// Trailer returns the Release-as trailer of the commit message.
+func (s Suggestion) Trailer() string {
+	return MappedVersionPrefix + s.Clib + "/" + s.MappedVersion
+}
+
+func (s *Suggestion) reason(format string, args ...any) {
+	s.Reasons = append(s.Reasons, fmt.Sprintf(format, args...))
+}
+
+// SuggestVersion applies the bumping rules to the llpkg.cfg of the submission
+// and the history in llpkgstore.json, and returns the next mapped version:
+//
+//   - the initial version, v1.0.0 for a stable C library, otherwise v0.1.0.
+//   - MAJOR for an upstream update changing the major version of the C library.
+//   - MINOR for other upstream updates newer than all mapped C versions.
+//   - PATCH for llpkg fixes of a mapped C version, or upstream updates on a legacy line,
+//     which must be submitted to the release branch.
+//
+// The suggestion always passes the checks of the PR verification.
+// See: https://github.com/goplus/llpkgstore/blob/main/docs/llpkgstore.md#version-mapping-rules
+func SuggestVersion(ver *versions.Versions, cfg config.LLPkgConfig) (Suggestion, error) {
+	clib := strings.TrimSpace(cfg.Upstream.Package.Name)
+	cversion := strings.TrimSpace(cfg.Upstream.Package.Version)
+	if clib == "" || cversion == "" {
+		return Suggestion{}, fmt.Errorf("actions: empty package name or version in llpkg.cfg")
+	}
+	current := versions.ToSemVer(cversion)
+	if semver.Prerelease(current) != "" {
+		return Suggestion{}, fmt.Errorf("actions: pre-release version %s is not accepted", cversion)
+	}
+
+	s := Suggestion{Clib: clib}
+	if len(ver.GoVersions(clib)) == 0 {
+		s.MappedVersion = versions.InitialVersion(cversion)
+		s.reason("%s has no mapped versions, this is its first release", clib)
+		if s.MappedVersion == "v0.1.0" {
+			s.reason("%s@%s is not stable (major version 0), so it starts with %s", clib, cversion, s.MappedVersion)
+		} else {
+			s.reason("%s@%s is stable, so it starts with %s", clib, cversion, s.MappedVersion)
+		}
+		return s, nil
+	}
+	latestGoVersion := ver.LatestGoVersion(clib)
+
+	cvers := ver.CVersions(clib)
+	if !semver.IsValid(current) || !versions.IsSemver(cvers) {
+		// versions can't be ordered, the only sensible choice is treating it as the latest
+		if mapped := ver.LatestGoVersionForCVersion(clib, cversion); mapped != "" {
+			s.MappedVersion = bumpVersion(latestGoVersion, bumpPatch)
+			s.reason("%s@%s is already mapped to %s, this is an llpkg fix: PATCH", clib, cversion, mapped)
+		} else {
+			s.MappedVersion = bumpVersion(latestGoVersion, bumpMinor)
+			s.reason("%s@%s is not mapped yet, this is an upstream update: MINOR", clib, cversion)
+		}
+		s.reason("C versions of %s don't follow semver, so the version is bumped from the latest %s", clib, latestGoVersion)
+		return s, nil
+	}
+	sort.Sort(versions.ByVersionDescending(cvers))
+	latestCVersion := cvers[0]
+
+	// upstream updates newer than all mapped C versions
+	if semver.Compare(current, latestCVersion) > 0 {
+		if semver.Major(current) != semver.Major(latestCVersion) {
+			s.MappedVersion = bumpVersion(latestGoVersion, bumpMajor)
+			s.reason("%s@%s changes the major version of the latest mapped C version %s, this is a breaking upstream update: MAJOR",
+				clib, cversion, ver.SearchBySemVer(clib, latestCVersion))
+		} else {
+			s.MappedVersion = bumpVersion(latestGoVersion, bumpMinor)
+			s.reason("%s@%s is newer than the latest mapped C version %s, this is an upstream update: MINOR",
+				clib, cversion, ver.SearchBySemVer(clib, latestCVersion))
+		}
+		s.reason("the latest mapped version is %s, so the next one is %s", latestGoVersion, s.MappedVersion)
+		return s, nil
+	}
+
+	// the closest C version not newer than us, and the closest one newer than us
+	i := sort.Search(len(cvers), func(i int) bool {
+		return semver.Compare(cvers[i], current) <= 0
+	})
+	var newer string
+	if i > 0 {
+		newer = cvers[i-1]
+		if semver.MajorMinor(newer) == semver.MajorMinor(current) {
+			return Suggestion{}, fmt.Errorf(`actions: cannot submit a historical legacy version, %s@%s is newer than %s.
+	for more details: https://github.com/goplus/llpkgstore/blob/main/docs/llpkgstore.md#prohibition-of-legacy-patch-maintenance`,
+				clib, ver.SearchBySemVer(clib, newer), cversion)
+		}
+	}
+	if i == len(cvers) {
+		return Suggestion{}, fmt.Errorf("actions: %s@%s is older than all mapped C versions, no mapped version is available for it", clib, cversion)
+	}
+	closest := ver.SearchBySemVer(clib, cvers[i])
+	closestGoVersion := ver.LatestGoVersionForCVersion(clib, closest)
+
+	s.MappedVersion = bumpVersion(closestGoVersion, bumpPatch)
+	if semver.Compare(cvers[i], current) == 0 {
+		s.reason("%s@%s is already mapped to %s, this is an llpkg fix: PATCH", clib, cversion, closestGoVersion)
+	} else {
+		s.reason("%s@%s is a legacy update after %s@%s mapped to %s, MINOR is taken by newer C versions: PATCH",
+			clib, cversion, clib, closest, closestGoVersion)
+	}
+
+	if newer != "" {
+		// the version ordering must be kept
+		newerGoVersions := slices.Clone(ver.MetadataMap[clib].Versions[ver.SearchBySemVer(clib, newer)])
+		semver.Sort(newerGoVersions)
+		if slices.Contains(ver.GoVersions(clib), s.MappedVersion) ||
+			semver.Compare(s.MappedVersion, newerGoVersions[0]) >= 0 {
+			return Suggestion{}, fmt.Errorf("actions: %s is taken by %s@%s, no mapped version is available between %s and %s",
+				s.MappedVersion, clib, ver.SearchBySemVer(clib, newer), closestGoVersion, newerGoVersions[0])
+		}
+		s.Branch = BranchPrefix + clib + "/" + closestGoVersion
+		s.reason("%s@%s is newer, so it must be submitted to the release branch %s", clib, ver.SearchBySemVer(clib, newer), s.Branch)
+	}
+	return s, nil
+}
+
+// components of semantic versions
+const (
+	bumpMajor = iota
+	bumpMinor
+	bumpPatch
+)
+
+// bumpVersion increments the component of the version, and resets the lower ones.
+// It's used with valid versions only, pre-release and build metadata are dropped.
+func bumpVersion(version string, component int) string {
+	var nums [3]int
+	release := strings.TrimSuffix(semver.Canonical(version), semver.Prerelease(version))
+	fmt.Sscanf(release, "v%d.%d.%d", &nums[0], &nums[1], &nums[2])
+	nums[component]++
+	for i := component + 1; i < len(nums); i++ {
+		nums[i] = 0
+	}
+	return fmt.Sprintf("v%d.%d.%d", nums[0], nums[1], nums[2])
+}
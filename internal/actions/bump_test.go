@@ -0,0 +1,108 @@
+package actions
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/goplus/llpkgstore/config"
+	"github.com/goplus/llpkgstore/internal/actions/versions"
+	"github.com/goplus/llpkgstore/metadata"
+)
+
+func TestSuggestVersion(t *testing.T) {
+	history := metadata.MetadataMap{
+		"cjson": &metadata.Metadata{
+			Versions: metadata.VersionMap{
+				"1.5.1": {"v1.0.0", "v1.0.1"},
+				"1.6":   {"v1.1.0"},
+				"1.7.1": {"v1.2.0"},
+			},
+		},
+		"libass": &metadata.Metadata{
+			Versions: metadata.VersionMap{
+				"0.17.1": {"v0.1.0"},
+			},
+		},
+		"crowded": &metadata.Metadata{
+			Versions: metadata.VersionMap{
+				"1.4.1": {"v1.0.0"},
+				"1.5.2": {"v1.0.1"},
+				"1.6.0": {"v1.1.0"},
+			},
+		},
+	}
+
+	tests := []struct {
+		clib, cversion string
+		expected       string
+		branch         string
+	}{
+		{"zlib", "1.3.1", "v1.0.0", ""},
+		{"libass", "0.17.3", "v0.2.0", ""},
+		{"libass", "0.17.1", "v0.1.1", ""},
+		{"libass", "1.0.0", "v1.0.0", ""},
+		{"cjson", "1.7.1", "v1.2.1", ""},
+		{"cjson", "1.7.2", "v1.3.0", ""},
+		{"cjson", "1.8", "v1.3.0", ""},
+		{"cjson", "2.0", "v2.0.0", ""},
+		{"cjson", "1.5.2", "v1.0.2", "release-branch.cjson/v1.0.1"},
+		{"cjson", "1.6", "v1.1.1", "release-branch.cjson/v1.1.0"},
+	}
+	for _, tc := range tests {
+		ver := &versions.Versions{MetadataMap: history}
+		cfg := config.LLPkgConfig{Upstream: config.UpstreamConfig{
+			Package: config.PackageConfig{Name: tc.clib, Version: tc.cversion},
+		}}
+		s, err := SuggestVersion(ver, cfg)
+		if err != nil {
+			t.Errorf("%s@%s: unexpected error: %v", tc.clib, tc.cversion, err)
+			continue
+		}
+		if s.MappedVersion != tc.expected || s.Branch != tc.branch || len(s.Reasons) == 0 {
+			t.Errorf("%s@%s: unexpected suggestion: %+v", tc.clib, tc.cversion, s)
+		}
+		if s.Trailer() != "Release-as: "+tc.clib+"/"+tc.expected {
+			t.Errorf("%s@%s: unexpected trailer: %s", tc.clib, tc.cversion, s.Trailer())
+		}
+		// the suggestion must pass the PR verification
+		if err := checkLegacyVersion(ver, cfg, s.MappedVersion, s.Branch != ""); err != nil {
+			t.Errorf("%s@%s: suggestion %s is rejected: %v", tc.clib, tc.cversion, s.MappedVersion, err)
+		}
+	}
+
+	for _, tc := range []struct {
+		clib, cversion string
+		err            string
+	}{
+		{"cjson", "1.5.0", "historical legacy version"},
+		{"cjson", "1.4.9", "older than all mapped C versions"},
+		{"cjson", "1.8.0-beta.1", "pre-release"},
+		{"crowded", "1.4.2", "no mapped version is available"},
+		{"cjson", "", "empty"},
+	} {
+		ver := &versions.Versions{MetadataMap: history}
+		cfg := config.LLPkgConfig{Upstream: config.UpstreamConfig{
+			Package: config.PackageConfig{Name: tc.clib, Version: tc.cversion},
+		}}
+		if s, err := SuggestVersion(ver, cfg); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s@%s: unexpected result: %+v %v", tc.clib, tc.cversion, s, err)
+		}
+	}
+}
+
+func TestBumpVersion(t *testing.T) {
+	for _, tc := range []struct {
+		version   string
+		component int
+		expected  string
+	}{
+		{"v1.2.3", bumpMajor, "v2.0.0"},
+		{"v1.2.3", bumpMinor, "v1.3.0"},
+		{"v1.2.3", bumpPatch, "v1.2.4"},
+		{"v0.1.0-rc.1", bumpPatch, "v0.1.1"},
+	} {
+		if got := bumpVersion(tc.version, tc.component); got != tc.expected {
+			t.Errorf("%s: unexpected result: want: %s got: %s", tc.version, tc.expected, got)
+		}
+	}
+}
@@ -118,6 +118,26 @@ func WorkflowRunID() (id int64, err error) {
 	return
 }
 
+// SigningKey returns the base64 encoded ed25519 private key for signing llpkgstore.json,
+// from LLPKGSTORE_SIGNING_KEY environment variable.
+func SigningKey() (key string, err error) {
+	key = os.Getenv("LLPKGSTORE_SIGNING_KEY")
+	if key == "" {
+		err = newEnvError("LLPKGSTORE_SIGNING_KEY")
+	}
+	return
+}
+
+// MappingFormat returns the mapping format llpkgstore.json is written in, map or array,
+// from LLPKGSTORE_MAPPING_FORMAT environment variable.
+func MappingFormat() (format string, err error) {
+	format = os.Getenv("LLPKGSTORE_MAPPING_FORMAT")
+	if format == "" {
+		err = newEnvError("LLPKGSTORE_MAPPING_FORMAT")
+	}
+	return
+}
+
 func EventFile() ([]byte, error) {
 	eventFileName := os.Getenv("GITHUB_EVENT_PATH")
 	if eventFileName == "" {
@@ -23,3 +23,7 @@ func wrapActionError(err error) error {
 func (a *actionError) Error() string {
 	return fmt.Sprintf("actions: %v", a.Err)
 }
+
+func (a *actionError) Unwrap() error {
+	return a.Err
+}
@@ -2,7 +2,6 @@ package llcppg
 
 import (
 	"encoding/hex"
-	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -122,25 +121,23 @@ func TestLLCppgCanHash(t *testing.T) {
 }
 
 func TestLLCppg(t *testing.T) {
-	os.Mkdir("testgenerate", 0777)
-	defer os.RemoveAll("testgenerate")
-	path, _ := filepath.Abs("testgenerate")
+	path := t.TempDir()
 	generator := New(path, "cjson", path)
 
-	os.WriteFile("testgenerate/llcppg.cfg", []byte(testLLCppgConfig), 0755)
-	os.WriteFile("testgenerate/llpkg.cfg", []byte(testLLPkgConfig), 0755)
+	os.WriteFile(filepath.Join(path, "llcppg.cfg"), []byte(testLLCppgConfig), 0644)
+	os.WriteFile(filepath.Join(path, "llpkg.cfg"), []byte(testLLPkgConfig), 0644)
 
-	cfg, err := config.ParseLLPkgConfig("testgenerate/llpkg.cfg")
+	cfg, err := config.ParseLLPkgConfig(filepath.Join(path, "llpkg.cfg"))
 	if err != nil {
-		log.Fatalf("parse config error: %v", err)
+		t.Fatalf("parse config error: %v", err)
 	}
 	uc, err := config.NewUpstreamFromConfig(cfg.Upstream)
 	if err != nil {
-		log.Fatal(err)
+		t.Fatal(err)
 	}
-	_, err = uc.Installer.Install(uc.Pkg, "testgenerate")
+	_, err = uc.Installer.Install(uc.Pkg, path)
 	if err != nil {
-		log.Fatal(err)
+		t.Fatal(err)
 	}
 
 	cmd := exec.Command("pkg-config", "--libs", "cjson")
@@ -165,7 +162,7 @@ func TestLLCppg(t *testing.T) {
 		t.Error(err)
 		return
 	}
-	os.WriteFile("testgenerate/cJSON.go", []byte("1234"), 0755)
+	os.WriteFile(filepath.Join(path, "cJSON.go"), []byte("1234"), 0644)
 	if err := generator.Check(filepath.Join(path, ".generate")); err == nil {
 		t.Error("unexpected check")
 		return
@@ -38,6 +38,16 @@ func ToSemVer(version string) string {
 	return semver
 }
 
+// InitialVersion returns the initial mapped version for the C library version.
+// A stable C library starts with v1.0.0, otherwise it starts with v0.1.0.
+// See: https://github.com/goplus/llpkgstore/blob/main/docs/llpkgstore.md#initial-version
+func InitialVersion(cversion string) string {
+	if semver.Major(ToSemVer(cversion)) == "v0" {
+		return "v0.1.0"
+	}
+	return "v1.0.0"
+}
+
 // IsSemver checks if all provided version strings are valid semantic versions
 func IsSemver(cversions []string) bool {
 	for _, cversion := range cversions {
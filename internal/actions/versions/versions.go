@@ -1,65 +1,76 @@
 package versions
 
 import (
+	"crypto/ed25519"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
-	"log"
 	"os"
 	"slices"
 
+	"github.com/goplus/llpkgstore/internal/file"
 	"github.com/goplus/llpkgstore/metadata"
 	"golang.org/x/mod/semver"
 )
 
+var ErrDuplicateVersion = errors.New("version has already existed")
+
 // Versions is a mapping table implement for Github Action only.
 // It's recommend to use another implement in llgo for common usage.
 type Versions struct {
 	metadata.MetadataMap
 
 	fileName string
+	format   metadata.MappingFormat
 }
 
 // appendVersion adds a new version to the slice while preventing duplicates.
-// It panics if the element already exists in the array to enforce uniqueness constraints.
+// It returns ErrDuplicateVersion if the element already exists in the array to enforce uniqueness constraints.
 // Parameters:
 //
 //	arr: Slice of versions to modify
 //	elem: Version to append
-func appendVersion(arr []string, elem string) []string {
+func appendVersion(arr []string, elem string) ([]string, error) {
 	if slices.Contains(arr, elem) {
-		log.Fatalf("version %s has already existed", elem)
+		return nil, fmt.Errorf("%s: %w", elem, ErrDuplicateVersion)
 	}
-	return append(arr, elem)
+	return append(arr, elem), nil
 }
 
 // Read initializes a Versions struct by reading version mappings from a file.
 // It creates the file if it doesn't exist and parses the JSON content into the MetadataMap.
+// The mapping format of the file is kept by the following writes.
+// It returns an error if the file can't be read or isn't valid JSON.
 // Parameters:
 //
 //	fileName: Path to the version mapping file
-func Read(fileName string) *Versions {
+func Read(fileName string) (*Versions, error) {
 	// read or create a file
 	f, err := os.OpenFile(fileName, os.O_CREATE|os.O_RDONLY, 0644)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 	defer f.Close()
 
 	b, err := io.ReadAll(f)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	m := metadata.MetadataMap{}
 
 	if len(b) > 0 {
-		json.Unmarshal(b, &m)
+		if err := json.Unmarshal(b, &m); err != nil {
+			return nil, fmt.Errorf("%s: %w", fileName, err)
+		}
 	}
 
 	return &Versions{
 		MetadataMap: m,
 		fileName:    f.Name(),
-	}
+		format:      metadata.DetectMappingFormat(b),
+	}, nil
 }
 
 // cVersions retrieves the version mappings for a specific C library.
@@ -132,6 +143,36 @@ func (v *Versions) LatestGoVersion(clib string) string {
 	return versions[len(versions)-1]
 }
 
+// metadata returns the metadata of the C library, creating an empty one if it doesn't exist.
+func (v *Versions) metadata(clib string) *metadata.Metadata {
+	clibVersions := v.MetadataMap[clib]
+	if clibVersions == nil {
+		clibVersions = &metadata.Metadata{
+			Versions: map[metadata.CVersion][]metadata.GoVersion{},
+		}
+		v.MetadataMap[clib] = clibVersions
+	}
+	return clibVersions
+}
+
+// SetPackageInfo replaces the descriptive fields of the C library.
+// The change is persisted by the next Write.
+func (v *Versions) SetPackageInfo(clib string, info metadata.PackageInfo) {
+	v.metadata(clib).PackageInfo = info
+}
+
+// Format returns the mapping format used by Write and String,
+// which is the one of the file read, metadata.MapFormat for a new file.
+func (v *Versions) Format() metadata.MappingFormat {
+	return v.format
+}
+
+// SetFormat sets the mapping format used by Write and String, converting the file on the next write.
+// Reading accepts both formats regardless.
+func (v *Versions) SetFormat(format metadata.MappingFormat) {
+	v.format = format
+}
+
 // Write records a new Go version mapping for a C library version and persists to file.
 // Parameters:
 //
@@ -140,27 +181,94 @@ func (v *Versions) LatestGoVersion(clib string) string {
 //	mappedVersion: The Go version to map with the C library version.
 //
 // It appends the Go version to the existing list for the C library version and saves the updated metadata.
-func (v *Versions) Write(clib, clibVersion, mappedVersion string) {
-	clibVersions := v.MetadataMap[clib]
-	if clibVersions == nil {
-		clibVersions = &metadata.Metadata{
-			Versions: map[metadata.CVersion][]metadata.GoVersion{},
-		}
-		v.MetadataMap[clib] = clibVersions
+// It returns ErrDuplicateVersion if the Go version is already mapped to the C library version.
+func (v *Versions) Write(clib, clibVersion, mappedVersion string) error {
+	if err := v.appendMapping(clib, clibVersion, mappedVersion); err != nil {
+		return err
 	}
-	versions := clibVersions.Versions[clibVersion]
+	// sync to disk
+	return v.sync()
+}
 
-	versions = appendVersion(versions, mappedVersion)
+// WriteRelease records a new Go version mapping for a C library version together with
+// its release details, and persists both to file at once, so the mapping is never
+// persisted without its release details.
+// It returns ErrDuplicateVersion if the Go version is already mapped to the C library version.
+func (v *Versions) WriteRelease(clib, clibVersion, mappedVersion string, release metadata.Release) error {
+	if err := v.appendMapping(clib, clibVersion, mappedVersion); err != nil {
+		return err
+	}
+	v.setRelease(clib, mappedVersion, release)
+	return v.sync()
+}
 
+// appendMapping appends the Go version to the list of the C library version in memory.
+func (v *Versions) appendMapping(clib, clibVersion, mappedVersion string) error {
+	clibVersions := v.metadata(clib)
+	versions, err := appendVersion(clibVersions.Versions[clibVersion], mappedVersion)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", clib, clibVersion, err)
+	}
 	clibVersions.Versions[clibVersion] = versions
-	// sync to disk
-	b, _ := json.Marshal(&v.MetadataMap)
+	return nil
+}
+
+// Retract marks a mapped Go version of the C library as retracted with the reason,
+// so that it's never selected as the latest version, and persists to file.
+// It returns an error if the version isn't mapped.
+func (v *Versions) Retract(clib, mappedVersion, reason string) error {
+	if !slices.Contains(v.GoVersions(clib), mappedVersion) {
+		return fmt.Errorf("version %s of %s doesn't exist", mappedVersion, clib)
+	}
+	clibVersions := v.metadata(clib)
+	if clibVersions.Retracted == nil {
+		clibVersions.Retracted = map[metadata.GoVersion]string{}
+	}
+	clibVersions.Retracted[mappedVersion] = reason
+	return v.sync()
+}
 
-	os.WriteFile(v.fileName, []byte(b), 0644)
+// SetRelease records the release details of a mapped Go version of the C library,
+// and persists to file. It returns an error if the version isn't mapped.
+func (v *Versions) SetRelease(clib, mappedVersion string, release metadata.Release) error {
+	if !slices.Contains(v.GoVersions(clib), mappedVersion) {
+		return fmt.Errorf("version %s of %s doesn't exist", mappedVersion, clib)
+	}
+	v.setRelease(clib, mappedVersion, release)
+	return v.sync()
+}
+
+// setRelease records the release details of a mapped Go version in memory.
+func (v *Versions) setRelease(clib, mappedVersion string, release metadata.Release) {
+	clibVersions := v.metadata(clib)
+	if clibVersions.Releases == nil {
+		clibVersions.Releases = map[metadata.GoVersion]*metadata.Release{}
+	}
+	clibVersions.Releases[mappedVersion] = &release
+}
+
+// sync persists the mapping table to file.
+// The file is replaced atomically, so it's never left truncated if the writing fails.
+func (v *Versions) sync() error {
+	b, err := metadata.Marshal(v.MetadataMap, v.format)
+	if err != nil {
+		return err
+	}
+	return file.WriteFileAtomic(v.fileName, b, 0644)
+}
+
+// Sign writes the detached signature of the persisted mapping table to the signature file,
+// e.g. llpkgstore.json.sig. It must be called after the last Write.
+func (v *Versions) Sign(key ed25519.PrivateKey) error {
+	b, err := os.ReadFile(v.fileName)
+	if err != nil {
+		return err
+	}
+	return file.WriteFileAtomic(v.fileName+metadata.SignatureSuffix, metadata.Sign(key, b), 0644)
 }
 
 // String returns the JSON representation of the Versions metadata.
 func (v *Versions) String() string {
-	b, _ := json.Marshal(&v.MetadataMap)
+	b, _ := metadata.Marshal(v.MetadataMap, v.format)
 	return string(b)
 }
@@ -2,13 +2,34 @@ package versions
 
 import (
 	"bytes"
+	"crypto/ed25519"
+	"errors"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/goplus/llpkgstore/metadata"
 	"golang.org/x/mod/semver"
 )
 
+func mustRead(t *testing.T, fileName string) *Versions {
+	t.Helper()
+	v, err := Read(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func mustWrite(t *testing.T, v *Versions, clib, clibVersion, mappedVersion string) {
+	t.Helper()
+	if err := v.Write(clib, clibVersion, mappedVersion); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestCVersions(t *testing.T) {
 	b := []byte(`{
 		"cgood": {
@@ -26,7 +47,7 @@ func TestCVersions(t *testing.T) {
 	}
 	defer os.Remove(path)
 
-	v := Read(path)
+	v := mustRead(t, path)
 	goVersion := v.GoVersions("cgood")
 	semver.Sort(goVersion)
 
@@ -71,7 +92,7 @@ func TestLatestVersion(t *testing.T) {
 	}
 	defer os.Remove(path)
 
-	v := Read(path)
+	v := mustRead(t, path)
 
 	if v.LatestGoVersion("cgood") != "v1.1.0" {
 		t.Errorf("unexpected latest version: want: v1.1.0 got: %s", v.LatestGoVersion("cgood"))
@@ -79,20 +100,20 @@ func TestLatestVersion(t *testing.T) {
 }
 
 func TestAppend(t *testing.T) {
-	v := Read("llpkgstore.json")
+	v := mustRead(t, "llpkgstore.json")
 	defer os.Remove("llpkgstore.json")
 
-	v.Write("cjson", "1.7.18", "v1.0.0")
-	v.Write("cjson", "1.7.19", "v1.0.2")
+	mustWrite(t, v, "cjson", "1.7.18", "v1.0.0")
+	mustWrite(t, v, "cjson", "1.7.19", "v1.0.2")
 
-	v = Read("llpkgstore.json")
+	v = mustRead(t, "llpkgstore.json")
 	//defer os.Remove("llpkgstore.json")
 
-	v.Write("cjson", "1.7.18", "v1.0.1")
-	v.Write("libxml", "1.45.1.4", "v1.0.0")
+	mustWrite(t, v, "cjson", "1.7.18", "v1.0.1")
+	mustWrite(t, v, "libxml", "1.45.1.4", "v1.0.0")
 
-	v = Read("llpkgstore.json")
-	v.Write("libxml", "1.45.1.5", "v1.0.1")
+	v = mustRead(t, "llpkgstore.json")
+	mustWrite(t, v, "libxml", "1.45.1.5", "v1.0.1")
 
 	b, _ := os.ReadFile("llpkgstore.json")
 
@@ -100,3 +121,200 @@ func TestAppend(t *testing.T) {
 		t.Error("unexpected append result")
 	}
 }
+
+func TestWriteDuplicate(t *testing.T) {
+	v := mustRead(t, "llpkgstore.json")
+	defer os.Remove("llpkgstore.json")
+
+	mustWrite(t, v, "cjson", "1.7.18", "v1.0.0")
+	if err := v.Write("cjson", "1.7.18", "v1.0.0"); !errors.Is(err, ErrDuplicateVersion) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	b, _ := os.ReadFile("llpkgstore.json")
+	if !bytes.Equal(b, []byte(`{"cjson":{"versions":{"1.7.18":["v1.0.0"]}}}`)) {
+		t.Errorf("unexpected write result: %s", b)
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "llpkgstore.json")
+	os.WriteFile(path, []byte(`{"cjson":`), 0644)
+	if _, err := Read(path); err == nil {
+		t.Error("unexpected behavior: invalid JSON is accepted")
+	}
+	if _, err := Read(filepath.Join(t.TempDir(), "missing", "llpkgstore.json")); err == nil {
+		t.Error("unexpected behavior: unreadable file is accepted")
+	}
+}
+
+func TestWriteAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "llpkgstore.json")
+	v := mustRead(t, path)
+	mustWrite(t, v, "cjson", "1.7.18", "v1.0.0")
+
+	// the file is replaced as a whole, and no temporary file is left
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 || entries[0].Name() != "llpkgstore.json" {
+		t.Errorf("unexpected files: %v", entries)
+	}
+
+}
+
+func TestInitialVersion(t *testing.T) {
+	for cversion, expected := range map[string]string{
+		"1.7.18": "v1.0.0",
+		"2.0":    "v1.0.0",
+		"0.17.3": "v0.1.0",
+		"0.1":    "v0.1.0",
+	} {
+		if got := InitialVersion(cversion); got != expected {
+			t.Errorf("unexpected initial version for %s: want: %s got: %s", cversion, expected, got)
+		}
+	}
+}
+
+func TestSetPackageInfo(t *testing.T) {
+	v := mustRead(t, "llpkgstore.json")
+	defer os.Remove("llpkgstore.json")
+
+	v.SetPackageInfo("cjson", metadata.PackageInfo{
+		Description: "Ultralightweight JSON parser in ANSI C",
+		License:     "MIT",
+		Tags:        []string{"json"},
+	})
+	mustWrite(t, v, "cjson", "1.7.18", "v1.0.0")
+
+	b, _ := os.ReadFile("llpkgstore.json")
+
+	if !bytes.Equal(b, []byte(`{"cjson":{"versions":{"1.7.18":["v1.0.0"]},"description":"Ultralightweight JSON parser in ANSI C","license":"MIT","tags":["json"]}}`)) {
+		t.Errorf("unexpected write result: %s", b)
+	}
+}
+
+func TestSign(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := mustRead(t, "llpkgstore.json")
+	defer os.Remove("llpkgstore.json")
+	defer os.Remove("llpkgstore.json.sig")
+
+	mustWrite(t, v, "cjson", "1.7.18", "v1.0.0")
+	if err := v.Sign(priv); err != nil {
+		t.Error(err)
+		return
+	}
+
+	b, _ := os.ReadFile("llpkgstore.json")
+	sig, _ := os.ReadFile("llpkgstore.json.sig")
+	if err := metadata.Verify([]ed25519.PublicKey{pub}, b, sig); err != nil {
+		t.Errorf("unexpected signature: %v", err)
+	}
+}
+
+func TestWriteArrayFormat(t *testing.T) {
+	v := mustRead(t, "llpkgstore.json")
+	defer os.Remove("llpkgstore.json")
+
+	v.SetFormat(metadata.ArrayFormat)
+	mustWrite(t, v, "cjson", "1.7.18", "v1.0.0")
+	mustWrite(t, v, "cjson", "1.7.18", "v1.0.1")
+
+	b, _ := os.ReadFile("llpkgstore.json")
+	if !bytes.Equal(b, []byte(`{"cjson":{"versions":[{"c":"1.7.18","go":["v1.0.0","v1.0.1"]}]}}`)) {
+		t.Errorf("unexpected write result: %s", b)
+	}
+
+	// read it back, the format is kept
+	v = mustRead(t, "llpkgstore.json")
+	if goVersions := v.GoVersions("cjson"); !reflect.DeepEqual(goVersions, []string{"v1.0.0", "v1.0.1"}) {
+		t.Errorf("unexpected read result: %v", goVersions)
+	}
+	if v.Format() != metadata.ArrayFormat {
+		t.Errorf("unexpected format: %v", v.Format())
+	}
+	mustWrite(t, v, "cjson", "1.7.19", "v1.1.0")
+	b, _ = os.ReadFile("llpkgstore.json")
+	if !bytes.Equal(b, []byte(`{"cjson":{"versions":[{"c":"1.7.18","go":["v1.0.0","v1.0.1"]},{"c":"1.7.19","go":["v1.1.0"]}]}}`)) {
+		t.Errorf("unexpected write result: %s", b)
+	}
+
+	// switch it back explicitly
+	v.SetFormat(metadata.MapFormat)
+	mustWrite(t, v, "cjson", "1.7.19", "v1.1.1")
+	if v := mustRead(t, "llpkgstore.json"); v.Format() != metadata.MapFormat {
+		t.Errorf("unexpected format: %v", v.Format())
+	}
+}
+
+func TestRetract(t *testing.T) {
+	v := mustRead(t, "llpkgstore.json")
+	defer os.Remove("llpkgstore.json")
+
+	mustWrite(t, v, "cjson", "1.7.18", "v1.0.0")
+	if err := v.Retract("cjson", "v1.0.0", "broken binary"); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := v.Retract("cjson", "v1.0.1", "not exist"); err == nil {
+		t.Error("unexpected behavior: retract a nonexistent version")
+	}
+
+	b, _ := os.ReadFile("llpkgstore.json")
+	if !bytes.Equal(b, []byte(`{"cjson":{"versions":{"1.7.18":["v1.0.0"]},"retracted":{"v1.0.0":"broken binary"}}}`)) {
+		t.Errorf("unexpected write result: %s", b)
+	}
+}
+
+func TestSetRelease(t *testing.T) {
+	v := mustRead(t, "llpkgstore.json")
+	defer os.Remove("llpkgstore.json")
+
+	mustWrite(t, v, "cjson", "1.7.18", "v1.0.0")
+	err := v.SetRelease("cjson", "v1.0.0", metadata.Release{
+		Time:   time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		Commit: "c0ffee",
+		Assets: []metadata.Asset{{
+			Name:     "cjson_linux_amd64.zip",
+			Platform: "linux/amd64",
+			URL:      "https://github.com/goplus/llpkg/releases/download/cjson/v1.0.0/cjson_linux_amd64.zip",
+			SHA256:   "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		}},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if err := v.SetRelease("cjson", "v1.0.1", metadata.Release{}); err == nil {
+		t.Error("unexpected behavior: set release of a nonexistent version")
+	}
+
+	b, _ := os.ReadFile("llpkgstore.json")
+	expected := `{"cjson":{"versions":{"1.7.18":["v1.0.0"]},"releases":{"v1.0.0":{"time":"2025-03-01T00:00:00Z","commit":"c0ffee","assets":[{"name":"cjson_linux_amd64.zip","platform":"linux/amd64","url":"https://github.com/goplus/llpkg/releases/download/cjson/v1.0.0/cjson_linux_amd64.zip","sha256":"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"}]}}}}`
+	if string(b) != expected {
+		t.Errorf("unexpected write result: %s", b)
+	}
+}
+
+func TestWriteRelease(t *testing.T) {
+	v := mustRead(t, "llpkgstore.json")
+	defer os.Remove("llpkgstore.json")
+
+	release := metadata.Release{Time: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), Commit: "c0ffee"}
+	if err := v.WriteRelease("cjson", "1.7.18", "v1.0.0", release); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := v.WriteRelease("cjson", "1.7.18", "v1.0.0", release); !errors.Is(err, ErrDuplicateVersion) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	b, _ := os.ReadFile("llpkgstore.json")
+	expected := `{"cjson":{"versions":{"1.7.18":["v1.0.0"]},"releases":{"v1.0.0":{"time":"2025-03-01T00:00:00Z","commit":"c0ffee"}}}}`
+	if string(b) != expected {
+		t.Errorf("unexpected write result: %s", b)
+	}
+}
@@ -0,0 +1,17 @@
+package file
+
+// Lock acquires an advisory exclusive lock on the file at path, creating it if necessary,
+// blocking until the lock is available. The lock is released by calling unlock.
+//
+// The lock is only respected by processes using Lock or RLock on the same path.
+func Lock(path string) (unlock func() error, err error) {
+	return lockFile(path, true)
+}
+
+// RLock acquires an advisory shared lock on the file at path, like Lock.
+// Multiple shared locks can be held at the same time, but not along with an exclusive lock.
+//
+// On platforms without flock, shared locks behave like exclusive locks.
+func RLock(path string) (unlock func() error, err error) {
+	return lockFile(path, false)
+}
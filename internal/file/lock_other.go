@@ -0,0 +1,38 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package file
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+)
+
+const (
+	lockPollInterval = 10 * time.Millisecond
+	// a lock file older than lockStaleAge is left by a crashed process.
+	lockStaleAge = 10 * time.Minute
+)
+
+// lockFile emulates flock by creating the lock file exclusively,
+// it's removed when the lock is released.
+func lockFile(path string, _ bool) (unlock func() error, err error) {
+	for {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			f.Close()
+			return func() error {
+				return os.Remove(path)
+			}, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > lockStaleAge {
+			os.Remove(path)
+			continue
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
@@ -0,0 +1,303 @@
+// Package goproxy implements the GOPROXY protocol for llpkg modules,
+// serving them from a local clone of the llpkg repository.
+//
+// Versions of github.com/goplus/llpkg/{clib} are the {clib}/vX.Y.Z tags created by
+// post-processing, and the module files are in the {clib} directory of the tagged commit.
+package goproxy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/goplus/llpkgstore/metadata"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	modzip "golang.org/x/mod/zip"
+)
+
+var (
+	// clibMatch matches the valid C library names, which are the directory names in llpkg
+	clibMatch = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+-]*$`)
+
+	ErrNotFound = errors.New("not found")
+)
+
+type Options func(*Proxy)
+
+// WithMetadata enables the C version aliases, e.g. github.com/goplus/llpkg/cjson@1.7.18,
+// which are resolved by the metadata manager. Retracted versions aren't
+// selected as the latest version either.
+func WithMetadata(mgr metadata.Manager) Options {
+	return func(p *Proxy) {
+		p.mgr = mgr
+	}
+}
+
+// Proxy is an http.Handler serving the GOPROXY protocol:
+//
+//	GET /{module}/@v/list            the versions, one per line
+//	GET /{module}/@v/{version}.info  the version and its commit time
+//	GET /{module}/@v/{version}.mod   the go.mod file
+//	GET /{module}/@v/{version}.zip   the module zip
+//	GET /{module}/@latest            the info of the latest version
+//
+// where {module} and {version} are escaped by module.EscapePath and module.EscapeVersion.
+type Proxy struct {
+	repoDir string
+	mgr     metadata.Manager
+}
+
+// New returns a proxy serving the llpkg repository cloned at repoDir.
+func New(repoDir string, opts ...Options) *Proxy {
+	p := &Proxy{repoDir: repoDir}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// info is the response of .info and @latest.
+type info struct {
+	Version string
+	Time    time.Time
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	err := p.serve(w, r)
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrNotFound):
+		// the go command treats 404 and 410 as not found, and tries the next proxy
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (p *Proxy) serve(w http.ResponseWriter, r *http.Request) error {
+	urlPath := strings.TrimPrefix(r.URL.Path, "/")
+	if escapedPath, ok := strings.CutSuffix(urlPath, "/@latest"); ok {
+		modPath, clib, err := parseModulePath(escapedPath)
+		if err != nil {
+			return err
+		}
+		v, err := p.latest(modPath, clib)
+		if err != nil {
+			return err
+		}
+		return p.serveInfo(w, clib, v)
+	}
+
+	escapedPath, file, ok := strings.Cut(urlPath, "/@v/")
+	if !ok {
+		return fmt.Errorf("%s: %w", r.URL.Path, ErrNotFound)
+	}
+	modPath, clib, err := parseModulePath(escapedPath)
+	if err != nil {
+		return err
+	}
+	if file == "list" {
+		versions, err := p.versions(modPath, clib)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=UTF-8")
+		for _, v := range versions {
+			fmt.Fprintln(w, v)
+		}
+		return nil
+	}
+
+	i := strings.LastIndex(file, ".")
+	if i < 0 {
+		return fmt.Errorf("%s: %w", r.URL.Path, ErrNotFound)
+	}
+	version, err := module.UnescapeVersion(file[:i])
+	if err != nil {
+		return fmt.Errorf("%s: %w", r.URL.Path, ErrNotFound)
+	}
+	ext := file[i:]
+
+	if ext == ".info" && version != semver.Canonical(version) {
+		// not a canonical version, it's a query like a C version
+		version, err = p.query(modPath, clib, version)
+		if err != nil {
+			return err
+		}
+		return p.serveInfo(w, clib, version)
+	}
+	if err := p.checkVersion(modPath, clib, version); err != nil {
+		return err
+	}
+
+	tag := clib + "/" + version
+	switch ext {
+	case ".info":
+		return p.serveInfo(w, clib, version)
+	case ".mod":
+		b, err := p.git("show", "refs/tags/"+tag+":"+clib+"/go.mod")
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=UTF-8")
+		_, err = w.Write(b)
+		return err
+	case ".zip":
+		return p.serveZip(w, r, module.Version{Path: modPath, Version: version}, tag, clib)
+	}
+	return fmt.Errorf("%s: %w", r.URL.Path, ErrNotFound)
+}
+
+// parseModulePath unescapes the module path and returns the C library name of it.
+func parseModulePath(escapedPath string) (modPath, clib string, err error) {
+	modPath, err = module.UnescapePath(escapedPath)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", escapedPath, ErrNotFound)
+	}
+	prefix, _, _ := module.SplitPathVersion(modPath)
+	clib, ok := strings.CutPrefix(prefix, metadata.ModulePathPrefix)
+	if !ok || !clibMatch.MatchString(clib) {
+		return "", "", fmt.Errorf("%s: %w", modPath, ErrNotFound)
+	}
+	return modPath, clib, nil
+}
+
+// versions returns the versions of the module from the tags, in ascending order.
+func (p *Proxy) versions(modPath, clib string) ([]string, error) {
+	out, err := p.git("tag", "--list", clib+"/v*")
+	if err != nil {
+		return nil, err
+	}
+	var versions []string
+	for _, tag := range strings.Fields(string(out)) {
+		version := strings.TrimPrefix(tag, clib+"/")
+		if semver.Canonical(version) != version || module.Check(modPath, version) != nil {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	semver.Sort(versions)
+	return versions, nil
+}
+
+// checkVersion reports ErrNotFound if the module doesn't have the version.
+func (p *Proxy) checkVersion(modPath, clib, version string) error {
+	versions, err := p.versions(modPath, clib)
+	if err != nil {
+		return err
+	}
+	for _, v := range versions {
+		if v == version {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s@%s: %w", modPath, version, ErrNotFound)
+}
+
+// latest returns the latest version of the module, preferring releases over
+// pre-releases, and skipping the retracted ones if metadata is available.
+func (p *Proxy) latest(modPath, clib string) (string, error) {
+	versions, err := p.versions(modPath, clib)
+	if err != nil {
+		return "", err
+	}
+	var retracted map[metadata.GoVersion]string
+	if p.mgr != nil {
+		if m, err := p.mgr.MetadataByName(clib); err == nil {
+			retracted = m.Retracted
+		}
+	}
+
+	latest := ""
+	for i := len(versions) - 1; i >= 0; i-- {
+		v := versions[i]
+		if _, ok := retracted[v]; ok {
+			continue
+		}
+		if semver.Prerelease(v) == "" {
+			return v, nil
+		}
+		if latest == "" {
+			latest = v
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("%s: %w", modPath, ErrNotFound)
+	}
+	return latest, nil
+}
+
+// query resolves a version query which isn't a canonical version,
+// like the C version alias 1.7.18, against the metadata.
+func (p *Proxy) query(modPath, clib, query string) (string, error) {
+	if query == "latest" {
+		return p.latest(modPath, clib)
+	}
+	if p.mgr == nil {
+		return "", fmt.Errorf("%s@%s: %w", modPath, query, ErrNotFound)
+	}
+	resolved, err := p.mgr.Resolve(clib + "@" + query)
+	if err != nil || resolved.ModulePath != modPath {
+		return "", fmt.Errorf("%s@%s: %w", modPath, query, ErrNotFound)
+	}
+	if err := p.checkVersion(modPath, clib, resolved.GoVersion); err != nil {
+		return "", err
+	}
+	return resolved.GoVersion, nil
+}
+
+func (p *Proxy) serveInfo(w http.ResponseWriter, clib, version string) error {
+	out, err := p.git("log", "-1", "--format=%cI", "refs/tags/"+clib+"/"+version)
+	if err != nil {
+		return err
+	}
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(string(out)))
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(info{Version: version, Time: t.UTC()})
+}
+
+// serveZip creates the module zip in a temporary file before responding,
+// so that a failure is reported by the status code instead of a truncated zip.
+func (p *Proxy) serveZip(w http.ResponseWriter, r *http.Request, m module.Version, tag, clib string) error {
+	f, err := os.CreateTemp("", "llpkg-goproxy-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if err := modzip.CreateFromVCS(f, m, p.repoDir, "refs/tags/"+tag, clib); err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	http.ServeContent(w, r, "", time.Time{}, f)
+	return nil
+}
+
+// git runs the git command in the repository.
+func (p *Proxy) git(args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", p.repoDir}, args...)...)
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, exitErr.Stderr)
+		}
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,160 @@
+package goproxy
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goplus/llpkgstore/metadata"
+)
+
+// newTestRepo creates an llpkg repository with the tags of cjson.
+func newTestRepo(t *testing.T) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v %s", args, err, out)
+		}
+	}
+	commit := func(modPath, content, tag string) {
+		os.MkdirAll(filepath.Join(dir, "cjson"), 0755)
+		os.WriteFile(filepath.Join(dir, "cjson", "go.mod"), []byte("module "+modPath+"\n\ngo 1.22\n"), 0644)
+		os.WriteFile(filepath.Join(dir, "cjson", "cjson.go"), []byte(content), 0644)
+		git("add", "-A")
+		git("commit", "-q", "-m", tag)
+		git("tag", tag)
+	}
+	git("init", "-q")
+	commit("github.com/goplus/llpkg/cjson", "package cjson\n", "cjson/v1.0.0")
+	commit("github.com/goplus/llpkg/cjson", "package cjson\n\nconst A = 1\n", "cjson/v1.1.0")
+	commit("github.com/goplus/llpkg/cjson", "package cjson\n\nconst A = 2\n", "cjson/v1.1.1")
+	commit("github.com/goplus/llpkg/cjson/v2", "package cjson\n\nconst B = 1\n", "cjson/v2.0.0")
+	return dir
+}
+
+func get(t *testing.T, url string) (int, []byte) {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, b
+}
+
+func TestProxy(t *testing.T) {
+	repoDir := newTestRepo(t)
+	mgr := metadata.NewMemoryManager(metadata.MetadataMap{
+		"cjson": &metadata.Metadata{
+			Versions: metadata.VersionMap{
+				"1.7.17": {"v1.0.0"},
+				"1.7.18": {"v1.1.0", "v1.1.1"},
+				"2.0.0":  {"v2.0.0"},
+			},
+			Retracted: map[metadata.GoVersion]string{"v1.1.1": "broken"},
+		},
+	})
+	server := httptest.NewServer(New(repoDir, WithMetadata(mgr)))
+	defer server.Close()
+	base := server.URL + "/github.com/goplus/llpkg/cjson"
+
+	if code, b := get(t, base+"/@v/list"); code != http.StatusOK || string(b) != "v1.0.0\nv1.1.0\nv1.1.1\n" {
+		t.Errorf("unexpected list: %d %s", code, b)
+	}
+	if code, b := get(t, base+"/v2/@v/list"); code != http.StatusOK || string(b) != "v2.0.0\n" {
+		t.Errorf("unexpected list: %d %s", code, b)
+	}
+
+	infoTests := []struct {
+		path     string
+		expected string
+	}{
+		{"/@v/v1.1.0.info", "v1.1.0"},
+		{"/@latest", "v1.1.0"}, // v1.1.1 is retracted
+		{"/@v/1.7.18.info", "v1.1.0"},
+		{"/@v/1.7.info", "v1.1.0"},
+		{"/v2/@v/2.0.0.info", "v2.0.0"},
+		{"/v2/@latest", "v2.0.0"},
+	}
+	for _, tc := range infoTests {
+		code, b := get(t, base+tc.path)
+		var i info
+		if code != http.StatusOK || json.Unmarshal(b, &i) != nil || i.Version != tc.expected || i.Time.IsZero() {
+			t.Errorf("%s: unexpected info: %d %s", tc.path, code, b)
+		}
+	}
+
+	if code, b := get(t, base+"/v2/@v/v2.0.0.mod"); code != http.StatusOK || !strings.HasPrefix(string(b), "module github.com/goplus/llpkg/cjson/v2\n") {
+		t.Errorf("unexpected go.mod: %d %s", code, b)
+	}
+
+	code, b := get(t, base+"/@v/v1.1.1.zip")
+	if code != http.StatusOK {
+		t.Fatalf("unexpected zip: %d %s", code, b)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	if strings.Join(names, ",") != "github.com/goplus/llpkg/cjson@v1.1.1/cjson.go,github.com/goplus/llpkg/cjson@v1.1.1/go.mod" {
+		t.Errorf("unexpected zip files: %v", names)
+	}
+
+	for _, path := range []string{
+		"/@v/v1.2.0.info",
+		"/@v/v2.0.0.mod", // v2 requires the major version suffix
+		"/@v/1.8.0.info",
+		"/@v/v1.0.0.txt",
+		"/v3/@latest",
+	} {
+		if code, _ := get(t, base+path); code != http.StatusNotFound {
+			t.Errorf("%s: unexpected status: %d", path, code)
+		}
+	}
+	if code, _ := get(t, server.URL+"/github.com/other/cjson/@v/list"); code != http.StatusNotFound {
+		t.Errorf("unexpected status: %d", code)
+	}
+}
+
+func TestProxyZipError(t *testing.T) {
+	repoDir := newTestRepo(t)
+	// a file name which isn't allowed in module zips
+	os.WriteFile(filepath.Join(repoDir, "cjson", "aux.go"), []byte("package cjson\n"), 0644)
+	for _, args := range [][]string{
+		{"add", "-A"},
+		{"commit", "-q", "-m", "invalid"},
+		{"tag", "cjson/v1.2.0"},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", repoDir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v %s", args, err, out)
+		}
+	}
+	server := httptest.NewServer(New(repoDir))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/github.com/goplus/llpkg/cjson/@v/v1.2.0.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError || resp.Header.Get("Content-Type") == "application/zip" {
+		t.Errorf("unexpected response: %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+}
@@ -0,0 +1,237 @@
+// Package server serves a local llpkgstore.json and the query API over HTTP,
+// so that a private store can be hosted and llgo pointed at it with metadata.WithBaseURL.
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/goplus/llpkgstore/metadata"
+)
+
+// StoreFileName is the route of llpkgstore.json, the same as the published store.
+const StoreFileName = "llpkgstore.json"
+
+type Options func(*Server)
+
+// WithMaxAge sets the max-age of Cache-Control of llpkgstore.json,
+// clients don't revalidate their caches within it. Zero means revalidating every time.
+func WithMaxAge(maxAge time.Duration) Options {
+	return func(s *Server) {
+		s.maxAge = maxAge
+	}
+}
+
+// Server serves llpkgstore.json and the query API:
+//
+//	GET /llpkgstore.json            the store, with Last-Modified and ETag validators
+//	GET /llpkgstore.json.sig        the detached signature, if it exists
+//	GET /api/v1/resolve?q={query}   the result of metadata.Resolve
+//	GET /api/v1/packages/{name}     the metadata of the package
+//	GET /api/v1/search?q={query}    the result of metadata.Search
+//
+// The store file is reloaded once it's modified, so it can be updated in place.
+type Server struct {
+	storePath string
+	maxAge    time.Duration
+	mux       *http.ServeMux
+
+	mu    sync.Mutex
+	store *store
+}
+
+// store is a loaded version of the store file.
+type store struct {
+	modTime time.Time
+	size    int64
+	data    []byte
+	etag    string
+	mgr     metadata.Manager
+}
+
+// New returns a server of the store file at storePath.
+func New(storePath string, opts ...Options) *Server {
+	s := &Server{storePath: storePath, mux: http.NewServeMux()}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.mux.HandleFunc("GET /"+StoreFileName, s.serveStore)
+	s.mux.HandleFunc("GET /"+StoreFileName+metadata.SignatureSuffix, s.serveSignature)
+	s.mux.HandleFunc("GET /api/v1/resolve", s.serveResolve)
+	s.mux.HandleFunc("GET /api/v1/packages/{name}", s.servePackage)
+	s.mux.HandleFunc("GET /api/v1/search", s.serveSearch)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// load returns the store, reloading it if the file is modified.
+func (s *Server) load() (*store, error) {
+	fi, err := os.Stat(s.storePath)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.store != nil && s.store.modTime.Equal(fi.ModTime()) && s.store.size == fi.Size() {
+		return s.store, nil
+	}
+
+	data, err := os.ReadFile(s.storePath)
+	if err != nil {
+		return nil, err
+	}
+	var m metadata.MetadataMap
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.storePath, err)
+	}
+	sum := sha256.Sum256(data)
+	s.store = &store{
+		modTime: fi.ModTime(),
+		size:    fi.Size(),
+		data:    data,
+		etag:    `"` + hex.EncodeToString(sum[:]) + `"`,
+		mgr:     metadata.NewMemoryManager(m),
+	}
+	return s.store, nil
+}
+
+// serveStore serves llpkgstore.json, conditional requests are handled by http.ServeContent.
+func (s *Server) serveStore(w http.ResponseWriter, r *http.Request) {
+	st, err := s.load()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", st.etag)
+	w.Header().Set("Cache-Control", s.cacheControl())
+	http.ServeContent(w, r, StoreFileName, st.modTime, bytes.NewReader(st.data))
+}
+
+func (s *Server) serveSignature(w http.ResponseWriter, r *http.Request) {
+	f, err := os.Open(s.storePath + metadata.SignatureSuffix)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Cache-Control", s.cacheControl())
+	http.ServeContent(w, r, filepath.Base(f.Name()), fi.ModTime(), f)
+}
+
+func (s *Server) cacheControl() string {
+	if s.maxAge <= 0 {
+		return "no-cache"
+	}
+	return "max-age=" + strconv.Itoa(int(s.maxAge/time.Second))
+}
+
+// resolveResponse is the response of /api/v1/resolve.
+type resolveResponse struct {
+	Name       string `json:"name"`
+	ModulePath string `json:"module"`
+	GoVersion  string `json:"version"`
+	CVersion   string `json:"cversion"`
+}
+
+func (s *Server) serveResolve(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		writeError(w, http.StatusBadRequest, errors.New("missing query parameter q"))
+		return
+	}
+	st, err := s.load()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	resolved, err := st.mgr.Resolve(query)
+	if err != nil {
+		writeError(w, queryErrorStatus(err), err)
+		return
+	}
+	writeJSON(w, resolveResponse{
+		Name:       resolved.Name,
+		ModulePath: resolved.ModulePath,
+		GoVersion:  resolved.GoVersion,
+		CVersion:   resolved.CVersion,
+	})
+}
+
+func (s *Server) servePackage(w http.ResponseWriter, r *http.Request) {
+	st, err := s.load()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	m, err := st.mgr.MetadataByName(r.PathValue("name"))
+	if err != nil {
+		writeError(w, queryErrorStatus(err), err)
+		return
+	}
+	writeJSON(w, &m)
+}
+
+// searchResult is an item of the response of /api/v1/search.
+type searchResult struct {
+	Name  string `json:"name"`
+	Score int    `json:"score"`
+}
+
+func (s *Server) serveSearch(w http.ResponseWriter, r *http.Request) {
+	st, err := s.load()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	m, err := st.mgr.AllMetadata()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	results := []searchResult{}
+	for _, result := range metadata.Search(m, r.URL.Query().Get("q")) {
+		results = append(results, searchResult{Name: result.Name, Score: result.Score})
+	}
+	writeJSON(w, results)
+}
+
+// queryErrorStatus maps the errors of the manager to HTTP status codes.
+func queryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, metadata.ErrMetadataNotInCache), errors.Is(err, metadata.ErrNoMatchingVersion):
+		return http.StatusNotFound
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goplus/llpkgstore/metadata"
+)
+
+const testStore = `{"cjson": {"versions": {"1.7.17": ["v1.0.0"], "1.7.18": ["v1.1.0", "v1.1.1"], "1.8.0": ["v1.2.0"]}}}`
+
+func newTestServer(t *testing.T) (*httptest.Server, string) {
+	storePath := filepath.Join(t.TempDir(), StoreFileName)
+	if err := os.WriteFile(storePath, []byte(testStore), 0644); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(New(storePath))
+	t.Cleanup(server.Close)
+	return server, storePath
+}
+
+func TestServeStore(t *testing.T) {
+	server, storePath := newTestServer(t)
+
+	resp, err := http.Get(server.URL + "/" + StoreFileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" || resp.Header.Get("Last-Modified") == "" {
+		t.Fatalf("unexpected response: %d %v", resp.StatusCode, resp.Header)
+	}
+
+	req, _ := http.NewRequest("GET", server.URL+"/"+StoreFileName, nil)
+	req.Header.Set("If-None-Match", etag)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotModified {
+		t.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	// the store is reloaded once it's modified
+	os.WriteFile(storePath, []byte(`{"zlib": {"versions": {"1.3.1": ["v1.0.0"]}}}`), 0644)
+	os.Chtimes(storePath, time.Now().Add(time.Hour), time.Now().Add(time.Hour))
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") == etag {
+		t.Errorf("unexpected response: %d %v", resp.StatusCode, resp.Header)
+	}
+
+	resp, err = http.Get(server.URL + "/" + StoreFileName + metadata.SignatureSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+}
+
+func TestServeStore_MetadataManager(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	mgr, err := metadata.NewMetadataMgr(t.TempDir(), metadata.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest, err := mgr.LatestGoVer("cjson"); err != nil || latest != "v1.2.0" {
+		t.Errorf("unexpected result: %s %v", latest, err)
+	}
+}
+
+func TestServeAPI(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	tests := []struct {
+		path     string
+		status   int
+		expected string
+	}{
+		{"/api/v1/resolve?q=cjson@1.7", http.StatusOK, `{"name":"cjson","module":"github.com/goplus/llpkg/cjson","version":"v1.1.1","cversion":"1.7.18"}`},
+		{"/api/v1/resolve?q=cjson@3", http.StatusNotFound, ""},
+		{"/api/v1/resolve?q=unknown", http.StatusNotFound, ""},
+		{"/api/v1/resolve?q=" + "example.com/cjson", http.StatusBadRequest, ""},
+		{"/api/v1/resolve", http.StatusBadRequest, ""},
+		{"/api/v1/packages/cjson", http.StatusOK, `{"versions":{"1.7.17":["v1.0.0"],"1.7.18":["v1.1.0","v1.1.1"],"1.8.0":["v1.2.0"]}}`},
+		{"/api/v1/packages/unknown", http.StatusNotFound, ""},
+		{"/api/v1/search?q=json", http.StatusOK, `[{"name":"cjson","score":60}]`},
+		{"/api/v1/search?q=zlib", http.StatusOK, `[]`},
+	}
+	for _, tc := range tests {
+		resp, err := http.Get(server.URL + tc.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body json.RawMessage
+		json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+
+		if resp.StatusCode != tc.status {
+			t.Errorf("%s: unexpected status: %d %s", tc.path, resp.StatusCode, body)
+			continue
+		}
+		if tc.expected != "" && strings.TrimSpace(string(body)) != tc.expected {
+			t.Errorf("%s: unexpected body: %s", tc.path, body)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// Package site renders the static llpkg catalogue served by llpkg.goplus.org.
+package site
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/goplus/llpkgstore/config"
+	"github.com/goplus/llpkgstore/metadata"
+	"golang.org/x/mod/semver"
+)
+
+// StoreFileName is the name of the mapping table published alongside the pages.
+const StoreFileName = "llpkgstore.json"
+
+// latestVersionCount is the number of versions shown on a package card
+const latestVersionCount = 2
+
+var (
+	//go:embed templates
+	templateFS embed.FS
+	//go:embed static
+	staticFS embed.FS
+
+	indexTemplate = template.Must(template.ParseFS(templateFS, "templates/index.html"))
+)
+
+// page is the data of the index page.
+type page struct {
+	StoreFileName string
+	Packages      []pkg
+}
+
+// pkg is an llpkg rendered as a card and a modal.
+type pkg struct {
+	Name string
+	metadata.PackageInfo
+	ModulePath string
+	Latest     []version // latest versions which aren't retracted
+	Versions   []version // all versions, newest first
+
+	Upstream *config.PackageConfig // nil if llpkg.cfg isn't provided
+	ConanURL string                // empty if it's not installed by Conan
+}
+
+// version is a mapped version of an llpkg.
+type version struct {
+	Go        metadata.GoVersion
+	C         metadata.CVersion
+	Retracted string // reason, empty if not retracted
+	Time      time.Time
+}
+
+// Build renders the catalogue of store, the content of llpkgstore.json, to the out directory.
+//
+// configs holds the llpkg.cfg of the packages keyed by the package names,
+// packages without one are rendered from llpkgstore.json only.
+// store is copied to out as it is, so that its signature stays valid.
+func Build(out string, store []byte, configs map[string]config.LLPkgConfig) error {
+	var m metadata.MetadataMap
+	if err := json.Unmarshal(store, &m); err != nil {
+		return fmt.Errorf("%s: %w", StoreFileName, err)
+	}
+
+	p := page{StoreFileName: StoreFileName}
+	// the order is the same as searching with an empty query on the page
+	for _, result := range metadata.Search(m, "") {
+		if m[result.Name] == nil {
+			continue
+		}
+		cfg, ok := configs[result.Name]
+		var cfgPtr *config.LLPkgConfig
+		if ok {
+			cfgPtr = &cfg
+		}
+		p.Packages = append(p.Packages, newPkg(m, result.Name, cfgPtr))
+	}
+
+	var buf bytes.Buffer
+	if err := indexTemplate.Execute(&buf, &p); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(out, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(out, "index.html"), buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(out, StoreFileName), store, 0644); err != nil {
+		return err
+	}
+	return copyStatic(out)
+}
+
+func newPkg(m metadata.MetadataMap, name string, cfg *config.LLPkgConfig) pkg {
+	data := m[name]
+	p := pkg{
+		Name:        name,
+		PackageInfo: data.PackageInfo,
+		ModulePath:  metadata.ModulePathPrefix + name,
+	}
+	if resolved, err := metadata.Resolve(m, name); err == nil {
+		p.ModulePath = resolved.ModulePath
+	}
+
+	for _, entry := range data.Versions.Entries() {
+		for _, goVersion := range entry.Go {
+			v := version{Go: goVersion, C: entry.C, Retracted: data.Retracted[goVersion]}
+			if release := data.Releases[goVersion]; release != nil {
+				v.Time = release.Time
+			}
+			p.Versions = append(p.Versions, v)
+		}
+	}
+	slices.SortFunc(p.Versions, func(a, b version) int {
+		return semver.Compare(b.Go, a.Go)
+	})
+	for _, v := range p.Versions {
+		if len(p.Latest) == latestVersionCount {
+			break
+		}
+		if v.Retracted == "" {
+			p.Latest = append(p.Latest, v)
+		}
+	}
+
+	if cfg != nil {
+		// llpkgstore.json is authoritative, llpkg.cfg only fills the missing fields
+		if p.Description == "" {
+			p.Description = cfg.Description
+		}
+		if p.Homepage == "" {
+			p.Homepage = cfg.Homepage
+		}
+		if p.License == "" {
+			p.License = cfg.License
+		}
+		if len(p.Maintainers) == 0 {
+			p.Maintainers = cfg.Maintainers
+		}
+		if len(p.Tags) == 0 {
+			p.Tags = cfg.Tags
+		}
+		p.Upstream = &cfg.Upstream.Package
+		if cfg.Upstream.Installer.Name == "conan" {
+			p.ConanURL = conanURL(cfg.Upstream.Package)
+		}
+	}
+	return p
+}
+
+// conanURL returns the link to the recipe of the package on ConanCenter.
+func conanURL(pkg config.PackageConfig) string {
+	u := "https://conan.io/center/recipes/" + url.PathEscape(pkg.Name)
+	if pkg.Version != "" {
+		u += "?version=" + url.QueryEscape(pkg.Version)
+	}
+	return u
+}
+
+// copyStatic copies the embedded static files to out/static.
+func copyStatic(out string) error {
+	return fs.WalkDir(staticFS, "static", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(out, filepath.FromSlash(path))
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		b, err := staticFS.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, b, 0644)
+	})
+}
@@ -0,0 +1,77 @@
+package site
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goplus/llpkgstore/config"
+)
+
+func TestBuild(t *testing.T) {
+	store := []byte(`{
+	"cjson": {
+		"versions": {"1.7.17": ["v1.0.0"], "1.7.18": ["v1.1.0", "v1.1.1"]},
+		"retracted": {"v1.1.1": "broken binary"},
+		"description": "Ultralightweight JSON parser in ANSI C",
+		"tags": ["json"]
+	},
+	"zlib": {
+		"versions": {"1.3.1": ["v2.0.0"]}
+	}
+}`)
+	configs := map[string]config.LLPkgConfig{
+		"zlib": {
+			Description: "<A> compression library",
+			Upstream: config.UpstreamConfig{
+				Installer: config.InstallerConfig{Name: "conan"},
+				Package:   config.PackageConfig{Name: "zlib", Version: "1.3.1"},
+			},
+		},
+	}
+
+	out := t.TempDir()
+	if err := Build(out, store, configs); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(out, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	index := string(b)
+	for _, expected := range []string{
+		`data-name="cjson"`,
+		`<dialog id="pkg-cjson">`,
+		"Ultralightweight JSON parser in ANSI C",
+		"retracted: broken binary",
+		"&lt;A&gt; compression library",
+		"github.com/goplus/llpkg/zlib/v2",
+		"https://conan.io/center/recipes/zlib?version=1.3.1",
+	} {
+		if !strings.Contains(index, expected) {
+			t.Errorf("index.html doesn't contain %q", expected)
+		}
+	}
+	// retracted versions aren't shown on the card
+	card := index[strings.Index(index, `data-name="cjson"`):]
+	card = card[:strings.Index(card, "</article>")]
+	if strings.Contains(card, "v1.1.1") || !strings.Contains(card, "v1.1.0") || !strings.Contains(card, "v1.0.0") {
+		t.Errorf("unexpected card: %s", card)
+	}
+
+	copied, err := os.ReadFile(filepath.Join(out, StoreFileName))
+	if err != nil || string(copied) != string(store) {
+		t.Errorf("unexpected %s: %s %v", StoreFileName, copied, err)
+	}
+	for _, name := range []string{"static/search.js", "static/style.css"} {
+		if _, err := os.Stat(filepath.Join(out, name)); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if err := Build(t.TempDir(), []byte("{"), nil); err == nil {
+		t.Error("unexpected behavior: invalid store is accepted")
+	}
+}
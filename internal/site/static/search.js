@@ -0,0 +1,125 @@
+// Search of the llpkg catalogue, it ranks packages in the same way as
+// metadata.Search, keep them in sync.
+(function () {
+  "use strict";
+
+  const score = {
+    nameExact: 100,
+    namePrefix: 80,
+    nameSubstring: 60,
+    tagExact: 50,
+    tagSubstring: 40,
+    description: 30,
+    nameFuzzy: 20,
+  };
+
+  function isSubsequence(sub, s) {
+    let i = 0;
+    for (let j = 0; i < sub.length && j < s.length; j++) {
+      if (sub[i] === s[j]) {
+        i++;
+      }
+    }
+    return i === sub.length;
+  }
+
+  // matchScore returns the best score of the word matching the package, zero if not matched.
+  function matchScore(name, metadata, word) {
+    if (name === word) {
+      return score.nameExact;
+    }
+    if (name.startsWith(word)) {
+      return score.namePrefix;
+    }
+    if (name.includes(word)) {
+      return score.nameSubstring;
+    }
+
+    let best = 0;
+    if (metadata) {
+      for (const tag of (metadata.tags || []).map((t) => t.toLowerCase())) {
+        if (tag === word) {
+          return score.tagExact;
+        }
+        if (tag.includes(word)) {
+          best = score.tagSubstring;
+        }
+      }
+      if (best === 0 && (metadata.description || "").toLowerCase().includes(word)) {
+        best = score.description;
+      }
+    }
+    if (best === 0 && isSubsequence(word, name)) {
+      best = score.nameFuzzy;
+    }
+    return best;
+  }
+
+  function search(store, query) {
+    const words = query.toLowerCase().split(/\s+/).filter((w) => w !== "");
+    const results = [];
+    for (const [name, metadata] of Object.entries(store)) {
+      let total = 0;
+      for (const word of words) {
+        const wordScore = matchScore(name.toLowerCase(), metadata, word);
+        if (wordScore === 0) {
+          total = 0;
+          break;
+        }
+        total += wordScore;
+      }
+      if (total > 0 || words.length === 0) {
+        results.push({ name: name, score: total });
+      }
+    }
+    results.sort((a, b) => {
+      if (a.score !== b.score) {
+        return b.score - a.score;
+      }
+      if (a.name.length !== b.name.length) {
+        return a.name.length - b.name.length;
+      }
+      return a.name < b.name ? -1 : a.name > b.name ? 1 : 0;
+    });
+    return results;
+  }
+
+  const container = document.getElementById("packages");
+  const input = document.getElementById("search");
+  const noResults = document.getElementById("no-results");
+  const cards = new Map();
+  for (const card of container.querySelectorAll(".package")) {
+    cards.set(card.dataset.name, card);
+  }
+
+  container.addEventListener("click", (event) => {
+    const button = event.target.closest("[data-open]");
+    if (button) {
+      document.getElementById(button.dataset.open).showModal();
+    }
+  });
+
+  // the store is loaded once, searching doesn't require additional requests
+  fetch(container.dataset.store)
+    .then((resp) => resp.json())
+    .then((store) => {
+      function update() {
+        const results = search(store, input.value);
+        for (const card of cards.values()) {
+          card.hidden = true;
+        }
+        for (const result of results) {
+          const card = cards.get(result.name);
+          if (card) {
+            card.hidden = false;
+            container.appendChild(card);
+          }
+        }
+        noResults.hidden = results.length > 0;
+      }
+      input.addEventListener("input", update);
+      if (input.value !== "") {
+        update();
+      }
+    });
+})();
@@ -1,52 +1,112 @@
 package metadata
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/goplus/llpkgstore/internal/file"
 )
 
 var (
 	ErrCacheFileNotFound = errors.New("cache file not found")
+	ErrCacheCorrupt      = errors.New("cache file is corrupt")
+	ErrOffline           = errors.New("data not in local cache in offline mode")
 )
 
 // Cache represents a local cache for storing and retrieving data.
-// It binds a local file path and a remote data source URL
+// It binds a local file path and a remote data source URL.
+// It's safe for concurrent use by multiple goroutines.
 type Cache[T any] struct {
+	// mu guards the fields below it, which are replaced only by Update,
+	// and Update calls are serialized by updateMu.
+	updateMu sync.Mutex
+	mu       sync.RWMutex
+
 	data T // stores cached data
 
 	cacheFilePath string // local file path for cache storage
 	remoteUrl     string // URL of the remote data source
 
-	modTime time.Time // last modified time of the cached data
+	modTime   time.Time     // last modified time of the cached data
+	etag      string        // entity tag of the cached data
+	fetchTime time.Time     // time when the cached data was fetched or revalidated
+	maxAge    time.Duration // freshness lifetime of the cached data
+	bodySum   string        // hex encoded SHA-256 of the response body the data is decoded from
+
+	settings settings
+}
+
+// cacheState is the validators and freshness of the cached data,
+// it's kept in a sidecar file next to the cache file.
+type cacheState struct {
+	ETag         string    `json:"etag,omitempty"`
+	LastModified time.Time `json:"lastModified"`
+	FetchTime    time.Time `json:"fetchTime"`
+	MaxAge       int64     `json:"maxAge"` // in seconds
+	SHA256       string    `json:"sha256,omitempty"`
 }
 
 // NewCache initializes and loads the cache from disk or remote source
-func NewCache[T any](cacheFilePath, remoteUrl string) (*Cache[T], error) {
+func NewCache[T any](cacheFilePath, remoteUrl string, opts ...Options) (*Cache[T], error) {
 	cache := &Cache[T]{
 		cacheFilePath: cacheFilePath,
 		remoteUrl:     remoteUrl,
+		settings:      newSettings(opts),
 	}
 
 	err := cache.loadFromDisk()
+	if errors.Is(err, ErrCacheCorrupt) {
+		// keep the corrupt file for inspection, and never load it again
+		if qerr := cache.quarantine(); qerr != nil {
+			return nil, fmt.Errorf("error building cache: %v: %v", err, qerr)
+		}
+		// load it again, it may have been replaced by another process meanwhile
+		err = cache.loadFromDisk()
+	}
 	if err != nil {
+		if cache.settings.offline {
+			return nil, fmt.Errorf("error building cache: %w: %v", ErrOffline, err)
+		}
 		// local cache missing or invalid, fetch from remote
 		err = cache.Update()
 		if err != nil {
-			return nil, fmt.Errorf("error building cache: %v", err)
+			return nil, fmt.Errorf("error building cache: %w", err)
 		}
 	}
 
 	return cache, nil
 }
 
-// Update refreshes the cache by fetching remote data and saving to disk
+// Update refreshes the cache by fetching remote data and saving to disk.
+// It does nothing while the cached data is fresh.
+// In offline mode, it always returns ErrOffline.
 func (c *Cache[T]) Update() error {
+	return c.update(false)
+}
+
+// update refreshes the cache, even if the cached data is fresh when force is true.
+func (c *Cache[T]) update(force bool) error {
+	if c.settings.offline {
+		return ErrOffline
+	}
+	c.updateMu.Lock()
+	defer c.updateMu.Unlock()
+
+	if !force && !c.Stale() {
+		return nil
+	}
 	err := c.fetch()
 	if err != nil {
 		return err
@@ -60,17 +120,53 @@ func (c *Cache[T]) Update() error {
 	return nil
 }
 
-// fetch retrieves the latest data from the remote source using conditional requests
+// sources returns the URLs of the remote data, the primary one comes first,
+// followed by the mirrors in order.
+func (c *Cache[T]) sources() []string {
+	sources := []string{c.remoteUrl}
+	if len(c.settings.mirrors) == 0 {
+		return sources
+	}
+	// the data has the same path relative to the base URL in mirrors,
+	// fallback to the parent of the remote URL if base URL is unknown.
+	baseURL := c.settings.baseURL
+	if baseURL == "" || !strings.HasPrefix(c.remoteUrl, baseURL) {
+		baseURL = c.remoteUrl[:strings.LastIndex(c.remoteUrl, "/")+1]
+	}
+	path := strings.TrimPrefix(c.remoteUrl, baseURL)
+	for _, mirror := range c.settings.mirrors {
+		sources = append(sources, joinURL(mirror, path))
+	}
+	return sources
+}
+
+// fetch retrieves the latest data from the remote sources in order, until one succeeds
 func (c *Cache[T]) fetch() error {
+	var errs []error
+	for _, url := range c.sources() {
+		err := c.fetchFrom(url)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", url, err))
+	}
+	return errors.Join(errs...)
+}
+
+// fetchFrom retrieves the latest data from the url using conditional requests
+func (c *Cache[T]) fetchFrom(url string) error {
 	// Create HTTP request with If-Modified-Since header to reduce unnecessary downloads
-	req, err := http.NewRequest("GET", c.remoteUrl, nil)
+	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return err
 	}
+	if c.etag != "" {
+		req.Header.Set("If-None-Match", c.etag)
+	}
 	if !c.modTime.IsZero() {
 		req.Header.Set("If-Modified-Since", c.modTime.UTC().Format(http.TimeFormat))
 	}
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.settings.httpClient().Do(req)
 	if err != nil {
 		return err
 	}
@@ -78,6 +174,12 @@ func (c *Cache[T]) fetch() error {
 
 	switch resp.StatusCode {
 	case http.StatusNotModified:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.updateFreshness(resp.Header)
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			c.etag = etag
+		}
 		return nil
 	case http.StatusOK:
 		// Read and parse the response body
@@ -86,31 +188,138 @@ func (c *Cache[T]) fetch() error {
 			return err
 		}
 
+		// Refuse unsigned data if public keys are configured
+		if len(c.settings.publicKeys) > 0 {
+			sig, err := c.fetchSignature(url)
+			if err != nil {
+				return err
+			}
+			err = Verify(c.settings.publicKeys, body, sig)
+			if err != nil {
+				return err
+			}
+		}
+		if c.settings.verify != nil {
+			err = c.settings.verify(body)
+			if err != nil {
+				return err
+			}
+		}
+
 		var bodyData T
 		err = json.Unmarshal(body, &bodyData)
 		if err != nil {
 			return err
 		}
-		c.data = bodyData
 
 		// Update last modified time from response headers
+		modTime := c.modTime
 		lastModified := resp.Header.Get("Last-Modified")
 		if lastModified != "" {
-			c.modTime, err = time.Parse(http.TimeFormat, lastModified)
+			modTime, err = time.Parse(http.TimeFormat, lastModified)
 			if err != nil {
 				return err
 			}
 		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.data = bodyData
+		c.modTime = modTime
+		c.etag = resp.Header.Get("ETag")
+		sum := sha256.Sum256(body)
+		c.bodySum = hex.EncodeToString(sum[:])
+		c.updateFreshness(resp.Header)
 		return nil
 	default:
 		return fmt.Errorf("HTTP error %d: %s", resp.StatusCode, resp.Status)
 	}
 }
 
+// fetchSignature retrieves the detached signature of the data at url
+func (c *Cache[T]) fetchSignature(url string) ([]byte, error) {
+	resp, err := c.settings.httpClient().Get(url + SignatureSuffix)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: error fetching signature: HTTP error %d: %s",
+			ErrInvalidSignature, resp.StatusCode, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
 func (c *Cache[T]) Data() T {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.data
 }
 
+// sum returns the hex encoded SHA-256 of the response body the cached data is decoded from,
+// it's empty if unknown, e.g. the cache is written by older versions.
+func (c *Cache[T]) sum() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.bodySum
+}
+
+// Age returns the time elapsed since the cached data was fetched or revalidated,
+// it's the maximum duration if unknown.
+func (c *Cache[T]) Age() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.fetchTime.IsZero() {
+		return math.MaxInt64
+	}
+	return time.Since(c.fetchTime)
+}
+
+// Stale reports whether the cached data is older than the max-age
+// advertised by the remote source and needs to be revalidated.
+func (c *Cache[T]) Stale() bool {
+	age := c.Age()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return age >= c.maxAge
+}
+
+// updateFreshness records the response as the latest fetch time,
+// and the freshness lifetime from Cache-Control. c.mu must be held.
+func (c *Cache[T]) updateFreshness(header http.Header) {
+	c.fetchTime = time.Now()
+	// the response may have been cached by intermediaries for a while
+	if age, err := strconv.ParseInt(header.Get("Age"), 10, 64); err == nil && age > 0 {
+		c.fetchTime = c.fetchTime.Add(-time.Duration(age) * time.Second)
+	}
+	c.maxAge = parseMaxAge(header.Get("Cache-Control"))
+}
+
+// parseMaxAge returns the max-age directive of Cache-Control,
+// zero if it's absent or the response must be revalidated.
+func parseMaxAge(cacheControl string) time.Duration {
+	var maxAge time.Duration
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-cache", "no-store":
+			return 0
+		case "max-age":
+			seconds, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 64)
+			if err == nil && seconds > 0 {
+				maxAge = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return maxAge
+}
+
+// stateFilePath returns the path of the sidecar file keeping cacheState.
+func (c *Cache[T]) stateFilePath() string {
+	return c.cacheFilePath + ".state"
+}
+
 // saveToDisk persists the current cache data to the local file system
 func (c *Cache[T]) saveToDisk() error {
 	// Create directory structure if needed
@@ -120,37 +329,73 @@ func (c *Cache[T]) saveToDisk() error {
 	}
 
 	// Serialize data to JSON
-	file, err := json.Marshal(c.data)
+	data, err := json.Marshal(c.data)
+	if err != nil {
+		return err
+	}
+
+	// Hold the lock to keep the data and the state consistent among processes
+	unlock, err := file.Lock(c.lockFilePath())
 	if err != nil {
 		return err
 	}
+	defer unlock()
 
 	// Write to file with proper permissions
-	err = os.WriteFile(c.cacheFilePath, file, 0644)
+	err = file.WriteFileAtomic(c.cacheFilePath, data, 0644)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	// Write the state after the data, a stale state leads to a full fetch only.
+	state, err := json.Marshal(cacheState{
+		ETag:         c.etag,
+		LastModified: c.modTime,
+		FetchTime:    c.fetchTime,
+		MaxAge:       int64(c.maxAge / time.Second),
+		SHA256:       c.bodySum,
+	})
+	if err != nil {
+		return err
+	}
+	return file.WriteFileAtomic(c.stateFilePath(), state, 0644)
 }
 
 func (c *Cache[T]) loadFromDisk() error {
 	if c.exists() {
+		unlock, err := file.RLock(c.lockFilePath())
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
 		// Read the cache file.
-		file, err := os.ReadFile(c.cacheFilePath)
+		data, err := os.ReadFile(c.cacheFilePath)
 		if err != nil {
 			return fmt.Errorf("error read file from cache: %v", err)
 		}
 
 		// Unmarshal the cache file.
 		var fileData T
-		err = json.Unmarshal(file, &fileData)
+		err = json.Unmarshal(data, &fileData)
 		if err != nil {
-			return fmt.Errorf("error json unmarshal from cache: %v", err)
+			return fmt.Errorf("error json unmarshal from cache: %w: %v", ErrCacheCorrupt, err)
 		}
 		c.data = fileData
 
-		// Get the last modified time of the cache.
+		// Restore the validators from the sidecar file.
+		var state cacheState
+		if b, err := os.ReadFile(c.stateFilePath()); err == nil && json.Unmarshal(b, &state) == nil {
+			c.etag = state.ETag
+			c.modTime = state.LastModified
+			c.fetchTime = state.FetchTime
+			c.maxAge = time.Duration(state.MaxAge) * time.Second
+			c.bodySum = state.SHA256
+			return nil
+		}
+
+		// Fallback to the last modified time of the cache file,
+		// which is written by older versions without the sidecar file.
 		fileInfo, err := os.Stat(c.cacheFilePath)
 		if err != nil {
 			return err
@@ -163,6 +408,39 @@ func (c *Cache[T]) loadFromDisk() error {
 	return nil
 }
 
+// quarantine moves the corrupt cache file aside, with its state.
+// The file is validated again under the lock, so that a valid one written
+// by another process after it's found corrupt is never moved aside.
+func (c *Cache[T]) quarantine() error {
+	unlock, err := file.Lock(c.lockFilePath())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data, err := os.ReadFile(c.cacheFilePath)
+	if errors.Is(err, os.ErrNotExist) {
+		// it's quarantined by another process
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var fileData T
+	if json.Unmarshal(data, &fileData) == nil {
+		// it's replaced by another process
+		return nil
+	}
+
+	os.Remove(c.stateFilePath())
+	return os.Rename(c.cacheFilePath, c.cacheFilePath+".corrupt")
+}
+
+// lockFilePath returns the path of the file locked when accessing the cache.
+func (c *Cache[T]) lockFilePath() string {
+	return c.cacheFilePath + ".lock"
+}
+
 // Returns true if the local cache exists.
 func (c *Cache[T]) exists() bool {
 	_, err := os.Stat(c.cacheFilePath)
@@ -1,7 +1,9 @@
 package metadata
 
 import (
+	"crypto/ed25519"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -114,3 +116,256 @@ func TestCache_InvalidJSON(t *testing.T) {
 		t.Errorf("Expected error for invalid JSON response, but got nil")
 	}
 }
+
+// Test falling back to mirrors when the primary source fails
+func TestCache_MirrorFailover(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer primary.Close()
+
+	var requested []string
+	brokenMirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, "broken"+r.URL.Path)
+		w.Write([]byte(`invalid json`))
+	}))
+	defer brokenMirror.Close()
+
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, "mirror"+r.URL.Path)
+		json.NewEncoder(w).Encode(testCacheData)
+	}))
+	defer mirror.Close()
+
+	tmpDir := t.TempDir()
+	cache, err := NewCache[MetadataMap](filepath.Join(tmpDir, "cache.json"), primary.URL+"/store/llpkgstore.json",
+		WithBaseURL(primary.URL+"/store"), WithMirrors(brokenMirror.URL, mirror.URL+"/"))
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+
+	if !reflect.DeepEqual(requested, []string{"broken/llpkgstore.json", "mirror/llpkgstore.json"}) {
+		t.Errorf("Unexpected requests: %v", requested)
+	}
+	if !reflect.DeepEqual(cache.Data(), testCacheData) {
+		t.Errorf("Cache data mismatch. Expected: %v, Got: %v", testCacheData, cache.Data())
+	}
+}
+
+// Test all sources fail
+func TestCache_AllSourcesFail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	_, err := NewCache[MetadataMap](filepath.Join(tmpDir, "cache.json"), server.URL+"/llpkgstore.json",
+		WithMirrors(server.URL+"/mirror"))
+	if err == nil {
+		t.Errorf("Expected error when all sources fail, but got nil")
+	}
+}
+
+// Test revalidating with ETag and skipping the network while the data is fresh
+func TestCache_ETagAndMaxAge(t *testing.T) {
+	var requests, notModified int
+	maxAge := "max-age=600"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", maxAge)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		json.NewEncoder(w).Encode(testCacheData)
+	}))
+	defer server.Close()
+
+	cachePath := filepath.Join(t.TempDir(), "cache.json")
+	cache, err := NewCache[MetadataMap](cachePath, server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	if cache.Stale() || cache.Age() > time.Minute {
+		t.Errorf("unexpected stale cache: age %v", cache.Age())
+	}
+
+	// fresh data, no request is sent
+	if err := cache.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Errorf("unexpected requests: want 1 got %d", requests)
+	}
+
+	// the validators are restored from the sidecar file
+	cache, err = NewCache[MetadataMap](cachePath, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cache.etag != `"v1"` || cache.Stale() {
+		t.Errorf("unexpected state: etag %s stale %v", cache.etag, cache.Stale())
+	}
+
+	// expired data is revalidated by ETag
+	cache.fetchTime = time.Now().Add(-time.Hour)
+	maxAge = "no-cache"
+	if err := cache.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 || notModified != 1 {
+		t.Errorf("unexpected requests: want 2/1 got %d/%d", requests, notModified)
+	}
+	if !cache.Stale() {
+		t.Error("unexpected fresh cache with no-cache")
+	}
+	if !reflect.DeepEqual(cache.Data(), testCacheData) {
+		t.Errorf("Cache data mismatch. Expected: %v, Got: %v", testCacheData, cache.Data())
+	}
+}
+
+func TestParseMaxAge(t *testing.T) {
+	tests := map[string]time.Duration{
+		"":                       0,
+		"max-age=60":             time.Minute,
+		"public, max-age=3600":   time.Hour,
+		"max-age=60, no-cache":   0,
+		"no-store":               0,
+		"max-age=invalid":        0,
+		`private, MAX-AGE="120"`: 2 * time.Minute,
+	}
+	for cacheControl, want := range tests {
+		if got := parseMaxAge(cacheControl); got != want {
+			t.Errorf("parseMaxAge(%q): want %v got %v", cacheControl, want, got)
+		}
+	}
+}
+
+// Test recovering from a corrupt cache file
+func TestCache_CorruptRecovery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(testCacheData)
+	}))
+	defer server.Close()
+
+	cachePath := filepath.Join(t.TempDir(), "cache.json")
+	// a half-written file
+	if err := os.WriteFile(cachePath, []byte(`{"example-module":{"vers`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cache, err := NewCache[MetadataMap](cachePath, server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	if !reflect.DeepEqual(cache.Data(), testCacheData) {
+		t.Errorf("Cache data mismatch. Expected: %v, Got: %v", testCacheData, cache.Data())
+	}
+	if b, err := os.ReadFile(cachePath + ".corrupt"); err != nil || string(b) != `{"example-module":{"vers` {
+		t.Errorf("unexpected quarantined file: %s %v", b, err)
+	}
+}
+
+// Test keeping the cache file replaced after it's found corrupt
+func TestCache_QuarantineReplaced(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "cache.json")
+	cache := &Cache[MetadataMap]{cacheFilePath: cachePath}
+	if err := os.WriteFile(cachePath, []byte(`{"example-module":{"vers`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.loadFromDisk(); !errors.Is(err, ErrCacheCorrupt) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// another process writes a valid one before quarantining
+	b, _ := json.Marshal(testCacheData)
+	if err := os.WriteFile(cachePath, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.quarantine(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(cachePath + ".corrupt"); err == nil {
+		t.Error("unexpected behavior: valid cache file is quarantined")
+	}
+	if err := cache.loadFromDisk(); err != nil || !reflect.DeepEqual(cache.Data(), testCacheData) {
+		t.Errorf("unexpected cache data: %v %v", cache.Data(), err)
+	}
+}
+
+// Test sharing the cache file among concurrent writers and readers
+func TestCache_ConcurrentAccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(testCacheData)
+	}))
+	defer server.Close()
+
+	cachePath := filepath.Join(t.TempDir(), "cache.json")
+	errs := make(chan error, 16)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			cache, err := NewCache[MetadataMap](cachePath, server.URL)
+			if err == nil {
+				err = cache.Update()
+			}
+			errs <- err
+		}()
+	}
+	for i := 0; i < cap(errs); i++ {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+	if _, err := os.Stat(cachePath + ".corrupt"); err == nil {
+		t.Error("unexpected corrupt cache")
+	}
+}
+
+// Test refusing remote data without a valid signature
+func TestCache_SignatureVerification(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(testCacheData)
+	sig := Sign(priv, data)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/llpkgstore.json", "/unsigned.json":
+			w.Write(data)
+		case "/llpkgstore.json.sig":
+			w.Write(sig)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	cachePath := filepath.Join(t.TempDir(), "cache.json")
+	cache, err := NewCache[MetadataMap](cachePath, server.URL+"/llpkgstore.json", WithPublicKeys(pub))
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	if !reflect.DeepEqual(cache.Data(), testCacheData) {
+		t.Errorf("Cache data mismatch. Expected: %v, Got: %v", testCacheData, cache.Data())
+	}
+
+	// tampered data is refused, and the last good copy is kept
+	data = []byte(`{"example-module":{"versions":{"1.7.18":["v9.9.9"]}}}`)
+	if err := cache.Update(); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(cache.Data(), testCacheData) {
+		t.Errorf("Cache data mismatch. Expected: %v, Got: %v", testCacheData, cache.Data())
+	}
+
+	// unsigned data is refused on initialization
+	_, err = NewCache[MetadataMap](filepath.Join(t.TempDir(), "cache.json"), server.URL+"/unsigned.json", WithPublicKeys(pub))
+	if err == nil {
+		t.Error("unexpected behavior: unsigned data is accepted")
+	}
+}
@@ -0,0 +1,154 @@
+package metadata
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// Changes are the differences between two snapshots of the metadata map.
+type Changes struct {
+	Added    []string         `json:"added,omitempty"`    // names of the added packages
+	Removed  []string         `json:"removed,omitempty"`  // names of the removed packages
+	Packages []PackageChanges `json:"packages,omitempty"` // changes of the added and existing packages
+}
+
+// PackageChanges are the version changes of a package.
+type PackageChanges struct {
+	Name          string            `json:"name"`
+	NewCVersions  []CVersion        `json:"newCVersions,omitempty"`
+	NewGoVersions []GoVersionChange `json:"newGoVersions,omitempty"`
+	Retracted     []Retraction      `json:"retracted,omitempty"`
+}
+
+// GoVersionChange is a newly mapped Go version.
+type GoVersionChange struct {
+	GoVersion GoVersion `json:"go"`
+	CVersion  CVersion  `json:"c"`
+}
+
+// Retraction is a newly retracted Go version.
+type Retraction struct {
+	GoVersion GoVersion `json:"go"`
+	Reason    string    `json:"reason"`
+}
+
+// Diff returns the changes from oldMap to newMap,
+// all lists are sorted.
+func Diff(oldMap, newMap MetadataMap) Changes {
+	var changes Changes
+	for name := range oldMap {
+		if _, ok := newMap[name]; !ok {
+			changes.Removed = append(changes.Removed, name)
+		}
+	}
+	for name, metadata := range newMap {
+		if metadata == nil {
+			continue
+		}
+		oldMetadata, ok := oldMap[name]
+		if !ok {
+			changes.Added = append(changes.Added, name)
+		}
+		if oldMetadata == nil {
+			oldMetadata = &Metadata{}
+		}
+		if pkg := diffPackage(name, oldMetadata, metadata); !pkg.empty() {
+			changes.Packages = append(changes.Packages, pkg)
+		}
+	}
+
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Removed)
+	sort.Slice(changes.Packages, func(i, j int) bool {
+		return changes.Packages[i].Name < changes.Packages[j].Name
+	})
+	return changes
+}
+
+func diffPackage(name string, oldMetadata, newMetadata *Metadata) PackageChanges {
+	pkg := PackageChanges{Name: name}
+	oldGoVersions := map[GoVersion]bool{}
+	for _, goVersions := range oldMetadata.Versions {
+		for _, goVersion := range goVersions {
+			oldGoVersions[goVersion] = true
+		}
+	}
+
+	for cVersion, goVersions := range newMetadata.Versions {
+		if _, ok := oldMetadata.Versions[cVersion]; !ok {
+			pkg.NewCVersions = append(pkg.NewCVersions, cVersion)
+		}
+		for _, goVersion := range goVersions {
+			if !oldGoVersions[goVersion] {
+				pkg.NewGoVersions = append(pkg.NewGoVersions, GoVersionChange{goVersion, cVersion})
+			}
+		}
+	}
+	for goVersion, reason := range newMetadata.Retracted {
+		if !oldMetadata.IsRetracted(goVersion) {
+			pkg.Retracted = append(pkg.Retracted, Retraction{goVersion, reason})
+		}
+	}
+
+	slices.SortFunc(pkg.NewCVersions, compareCVersion)
+	slices.SortFunc(pkg.NewGoVersions, func(a, b GoVersionChange) int {
+		return semver.Compare(a.GoVersion, b.GoVersion)
+	})
+	slices.SortFunc(pkg.Retracted, func(a, b Retraction) int {
+		return semver.Compare(a.GoVersion, b.GoVersion)
+	})
+	return pkg
+}
+
+func (p PackageChanges) empty() bool {
+	return len(p.NewCVersions) == 0 && len(p.NewGoVersions) == 0 && len(p.Retracted) == 0
+}
+
+// Empty reports whether there is no change.
+func (c Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Packages) == 0
+}
+
+// Markdown renders the changes as a changelog in Markdown.
+func (c Changes) Markdown() string {
+	var b strings.Builder
+	if c.Empty() {
+		b.WriteString("No changes.\n")
+		return b.String()
+	}
+
+	writeList := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "## %s\n\n", title)
+		for _, item := range items {
+			fmt.Fprintf(&b, "- %s\n", item)
+		}
+		b.WriteString("\n")
+	}
+	writeList("New packages", c.Added)
+	writeList("Removed packages", c.Removed)
+
+	if len(c.Packages) > 0 {
+		b.WriteString("## Package updates\n\n")
+	}
+	for _, pkg := range c.Packages {
+		fmt.Fprintf(&b, "### %s\n\n", pkg.Name)
+		if len(pkg.NewCVersions) > 0 {
+			fmt.Fprintf(&b, "- New C versions: %s\n", strings.Join(pkg.NewCVersions, ", "))
+		}
+		for _, v := range pkg.NewGoVersions {
+			fmt.Fprintf(&b, "- New version %s (C version %s)\n", v.GoVersion, v.CVersion)
+		}
+		for _, r := range pkg.Retracted {
+			fmt.Fprintf(&b, "- Retracted %s: %s\n", r.GoVersion, r.Reason)
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
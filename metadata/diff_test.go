@@ -0,0 +1,75 @@
+package metadata
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	oldMap := MetadataMap{
+		"cjson": &Metadata{Versions: VersionMap{"1.7.17": {"v1.0.0"}}},
+		"zlib":  &Metadata{Versions: VersionMap{"1.3": {"v1.0.0"}}},
+	}
+	newMap := MetadataMap{
+		"cjson": &Metadata{
+			Versions: VersionMap{
+				"1.7.17": {"v1.0.0", "v1.0.1"},
+				"1.7.18": {"v1.1.0"},
+			},
+			Retracted: map[GoVersion]string{"v1.0.0": "broken binary"},
+		},
+		"libxml2": &Metadata{Versions: VersionMap{"2.13.4": {"v1.0.0"}}},
+	}
+
+	expected := Changes{
+		Added:   []string{"libxml2"},
+		Removed: []string{"zlib"},
+		Packages: []PackageChanges{
+			{
+				Name:          "cjson",
+				NewCVersions:  []CVersion{"1.7.18"},
+				NewGoVersions: []GoVersionChange{{"v1.0.1", "1.7.17"}, {"v1.1.0", "1.7.18"}},
+				Retracted:     []Retraction{{"v1.0.0", "broken binary"}},
+			},
+			{
+				Name:          "libxml2",
+				NewCVersions:  []CVersion{"2.13.4"},
+				NewGoVersions: []GoVersionChange{{"v1.0.0", "2.13.4"}},
+			},
+		},
+	}
+	changes := Diff(oldMap, newMap)
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("unexpected changes: %+v", changes)
+	}
+
+	markdown := `## New packages
+
+- libxml2
+
+## Removed packages
+
+- zlib
+
+## Package updates
+
+### cjson
+
+- New C versions: 1.7.18
+- New version v1.0.1 (C version 1.7.17)
+- New version v1.1.0 (C version 1.7.18)
+- Retracted v1.0.0: broken binary
+
+### libxml2
+
+- New C versions: 2.13.4
+- New version v1.0.0 (C version 2.13.4)
+`
+	if got := changes.Markdown(); got != markdown {
+		t.Errorf("unexpected markdown:\n%s", got)
+	}
+
+	if changes := Diff(newMap, newMap); !changes.Empty() || changes.Markdown() != "No changes.\n" {
+		t.Errorf("unexpected changes: %+v", changes)
+	}
+}
@@ -0,0 +1,144 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"golang.org/x/mod/semver"
+)
+
+// MappingFormat is the layout of the version mappings in llpkgstore.json.
+type MappingFormat int
+
+const (
+	// MapFormat is the legacy layout, which is understood by all clients:
+	//
+	//	"versions": {"1.3": ["v0.1.0", "v0.1.1"]}
+	MapFormat MappingFormat = iota
+	// ArrayFormat is the layout described in the design doc:
+	//
+	//	"versions": [{"c": "1.3", "go": ["v0.1.0", "v0.1.1"]}]
+	ArrayFormat
+)
+
+// ParseMappingFormat parses the name of a mapping format, map or array.
+func ParseMappingFormat(name string) (MappingFormat, error) {
+	switch name {
+	case "map":
+		return MapFormat, nil
+	case "array":
+		return ArrayFormat, nil
+	default:
+		return 0, fmt.Errorf("unknown mapping format: %s, expect map or array", name)
+	}
+}
+
+// DetectMappingFormat returns the mapping format of the encoded metadata map,
+// MapFormat if there're no mappings to tell.
+func DetectMappingFormat(b []byte) MappingFormat {
+	var m map[string]struct {
+		Versions json.RawMessage `json:"versions"`
+	}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return MapFormat
+	}
+	for _, metadata := range m {
+		if versions := bytes.TrimSpace(metadata.Versions); len(versions) > 0 && versions[0] == '[' {
+			return ArrayFormat
+		}
+	}
+	return MapFormat
+}
+
+// VersionMap maps a C version to its Go versions.
+// It decodes from both MapFormat and ArrayFormat.
+type VersionMap map[CVersion][]GoVersion
+
+// VersionEntry is a version mapping in ArrayFormat.
+type VersionEntry struct {
+	C  CVersion    `json:"c"`
+	Go []GoVersion `json:"go"`
+}
+
+// UnmarshalJSON decodes the version mappings, the format is detected automatically.
+func (m *VersionMap) UnmarshalJSON(b []byte) error {
+	if b := bytes.TrimSpace(b); len(b) == 0 || b[0] != '[' {
+		return json.Unmarshal(b, (*map[CVersion][]GoVersion)(m))
+	}
+
+	var entries []VersionEntry
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return err
+	}
+	versions := make(VersionMap, len(entries))
+	for _, entry := range entries {
+		if _, ok := versions[entry.C]; ok {
+			return fmt.Errorf("duplicate mappings of C version %s", entry.C)
+		}
+		versions[entry.C] = entry.Go
+	}
+	*m = versions
+	return nil
+}
+
+// Entries returns the version mappings in ArrayFormat, ordered by C version.
+func (m VersionMap) Entries() []VersionEntry {
+	entries := make([]VersionEntry, 0, len(m))
+	for c, goVersions := range m {
+		entries = append(entries, VersionEntry{C: c, Go: goVersions})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return compareCVersion(entries[i].C, entries[j].C) < 0
+	})
+	return entries
+}
+
+// arrayMetadata is the Metadata encoded in ArrayFormat,
+// Versions shadows the one of the embedded Metadata.
+type arrayMetadata struct {
+	Versions []VersionEntry `json:"versions"`
+	*Metadata
+}
+
+// Marshal encodes the metadata map in the given format.
+func Marshal(m MetadataMap, format MappingFormat) ([]byte, error) {
+	switch format {
+	case MapFormat:
+		return json.Marshal(m)
+	case ArrayFormat:
+		converted := make(map[string]arrayMetadata, len(m))
+		for name, metadata := range m {
+			if metadata == nil {
+				converted[name] = arrayMetadata{}
+				continue
+			}
+			converted[name] = arrayMetadata{
+				Versions: metadata.Versions.Entries(),
+				Metadata: metadata,
+			}
+		}
+		return json.Marshal(converted)
+	default:
+		return nil, fmt.Errorf("unknown mapping format: %d", format)
+	}
+}
+
+// compareCVersion compares C versions by semver if possible,
+// otherwise lexically.
+func compareCVersion(a, b string) int {
+	sa, sb := "v"+a, "v"+b
+	if semver.IsValid(sa) && semver.IsValid(sb) {
+		if c := semver.Compare(sa, sb); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
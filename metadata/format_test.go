@@ -0,0 +1,93 @@
+package metadata
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestVersionMap_Unmarshal(t *testing.T) {
+	// both formats can be mixed in a file
+	src := `{
+		"cgood": {"versions": [{"c": "1.3", "go": ["v0.1.0", "v0.1.1"]}, {"c": "1.3.1", "go": ["v1.1.0"]}]},
+		"cjson": {"versions": {"1.7.18": ["v1.0.0"]}, "license": "MIT"}
+	}`
+	expected := MetadataMap{
+		"cgood": &Metadata{
+			Versions: VersionMap{
+				"1.3":   {"v0.1.0", "v0.1.1"},
+				"1.3.1": {"v1.1.0"},
+			},
+		},
+		"cjson": &Metadata{
+			Versions:    VersionMap{"1.7.18": {"v1.0.0"}},
+			PackageInfo: PackageInfo{License: "MIT"},
+		},
+	}
+
+	var m MetadataMap
+	if err := json.Unmarshal([]byte(src), &m); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("unexpected result: %v", m)
+	}
+
+	dup := `{"cgood": {"versions": [{"c": "1.3", "go": ["v0.1.0"]}, {"c": "1.3", "go": ["v0.1.1"]}]}}`
+	if err := json.Unmarshal([]byte(dup), &m); err == nil {
+		t.Error("unexpected behavior: duplicate C versions are accepted")
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	m := MetadataMap{
+		"cgood": &Metadata{
+			Versions: VersionMap{
+				"1.10.0": {"v1.2.0"},
+				"1.3.1":  {"v1.1.0"},
+				"1.3":    {"v0.1.0", "v0.1.1"},
+			},
+			PackageInfo: PackageInfo{License: "MIT"},
+		},
+	}
+
+	b, err := Marshal(m, ArrayFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"cgood":{"versions":[{"c":"1.3","go":["v0.1.0","v0.1.1"]},{"c":"1.3.1","go":["v1.1.0"]},{"c":"1.10.0","go":["v1.2.0"]}],"license":"MIT"}}`
+	if string(b) != expected {
+		t.Errorf("unexpected result: %s", b)
+	}
+
+	// round trip
+	var decoded MetadataMap
+	if err := json.Unmarshal(b, &decoded); err != nil || !reflect.DeepEqual(decoded, m) {
+		t.Errorf("unexpected round trip result: %v %v", decoded, err)
+	}
+
+	b, err = Marshal(m, MapFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `{"cgood":{"versions":{"1.10.0":["v1.2.0"],"1.3":["v0.1.0","v0.1.1"],"1.3.1":["v1.1.0"]},"license":"MIT"}}`
+	if string(b) != expected {
+		t.Errorf("unexpected result: %s", b)
+	}
+}
+
+func TestDetectMappingFormat(t *testing.T) {
+	tests := map[string]MappingFormat{
+		``:   MapFormat,
+		`{}`: MapFormat,
+		`{"cjson": {"versions": {"1.7.18": ["v1.0.0"]}}}`:                   MapFormat,
+		`{"cjson": {"versions": [{"c": "1.7.18", "go": ["v1.0.0"]}]}}`:      ArrayFormat,
+		`{"cjson": {"license": "MIT"}, "zlib": {"versions": [ ]}}`:          ArrayFormat,
+		`{"cjson": {"versions": {}}, "zlib": {"versions": [{"c": "1.3"}]}}`: ArrayFormat,
+	}
+	for src, expected := range tests {
+		if format := DetectMappingFormat([]byte(src)); format != expected {
+			t.Errorf("unexpected format of %s: %v", src, format)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package metadata
+
+import "errors"
+
+// layeredMgr looks up metadata in the layers in order.
+type layeredMgr struct {
+	layers []Manager
+}
+
+// NewLayeredManager returns a metadata manager looking up the layers in order,
+// the result of the first successful layer wins, e.g. memory over disk over remote:
+//
+//	disk, _ := NewMetadataMgr(cacheDir, WithOffline())
+//	remote, _ := NewMetadataMgr(cacheDir)
+//	mgr := NewLayeredManager(NewMemoryManager(fixtures), disk, remote)
+//
+// AllMetadata merges the metadata of all layers, earlier layers take precedence by name.
+func NewLayeredManager(layers ...Manager) Manager {
+	return &layeredMgr{layers: layers}
+}
+
+// firstSuccess returns the result of the first layer succeeding in lookup,
+// or all errors of the layers if none succeeds.
+func firstSuccess[T any](layers []Manager, lookup func(Manager) (T, error)) (T, error) {
+	var errs []error
+	for _, layer := range layers {
+		ret, err := lookup(layer)
+		if err == nil {
+			return ret, nil
+		}
+		errs = append(errs, err)
+	}
+	var zero T
+	if len(errs) == 0 {
+		return zero, ErrMetadataNotInCache
+	}
+	return zero, errors.Join(errs...)
+}
+
+func (l *layeredMgr) AllMetadata() (MetadataMap, error) {
+	merged := MetadataMap{}
+	var errs []error
+	for i := len(l.layers) - 1; i >= 0; i-- {
+		all, err := l.layers[i].AllMetadata()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for name, metadata := range all {
+			merged[name] = metadata
+		}
+	}
+	// partial results are fine, as long as one layer works
+	if len(errs) == len(l.layers) && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return merged, nil
+}
+
+func (l *layeredMgr) MetadataByName(name string) (Metadata, error) {
+	return firstSuccess(l.layers, func(m Manager) (Metadata, error) { return m.MetadataByName(name) })
+}
+
+func (l *layeredMgr) ModuleExists(name string) (bool, error) {
+	var errs []error
+	for _, layer := range l.layers {
+		exists, err := layer.ModuleExists(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if exists {
+			return true, nil
+		}
+	}
+	if len(errs) == len(l.layers) && len(errs) > 0 {
+		return false, errors.Join(errs...)
+	}
+	return false, nil
+}
+
+func (l *layeredMgr) LatestCVer(name string) (string, error) {
+	return firstSuccess(l.layers, func(m Manager) (string, error) { return m.LatestCVer(name) })
+}
+
+func (l *layeredMgr) LatestGoVer(name string) (string, error) {
+	return firstSuccess(l.layers, func(m Manager) (string, error) { return m.LatestGoVer(name) })
+}
+
+func (l *layeredMgr) LatestGoVerFromCVer(name, cVer string) (string, error) {
+	return firstSuccess(l.layers, func(m Manager) (string, error) { return m.LatestGoVerFromCVer(name, cVer) })
+}
+
+func (l *layeredMgr) GoVersFromCVer(name, cVer string) ([]string, error) {
+	return firstSuccess(l.layers, func(m Manager) ([]string, error) { return m.GoVersFromCVer(name, cVer) })
+}
+
+func (l *layeredMgr) CVerFromGoVer(name, goVer string) (string, error) {
+	return firstSuccess(l.layers, func(m Manager) (string, error) { return m.CVerFromGoVer(name, goVer) })
+}
+
+func (l *layeredMgr) AllGoVersFromName(name string) ([]string, error) {
+	return firstSuccess(l.layers, func(m Manager) ([]string, error) { return m.AllGoVersFromName(name) })
+}
+
+func (l *layeredMgr) AllCVersFromName(name string) ([]string, error) {
+	return firstSuccess(l.layers, func(m Manager) ([]string, error) { return m.AllCVersFromName(name) })
+}
+
+func (l *layeredMgr) Resolve(query string) (Resolved, error) {
+	return firstSuccess(l.layers, func(m Manager) (Resolved, error) { return m.Resolve(query) })
+}
+
+func (l *layeredMgr) ReleaseInfo(name, goVer string) (Release, error) {
+	return firstSuccess(l.layers, func(m Manager) (Release, error) { return m.ReleaseInfo(name, goVer) })
+}
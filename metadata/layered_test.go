@@ -0,0 +1,63 @@
+package metadata
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMemoryManager(t *testing.T) {
+	mgr := NewMemoryManager(resolveTestData)
+
+	goVersions, err := mgr.GoVersFromCVer("cjson", "1.7.18")
+	if err != nil || !reflect.DeepEqual(goVersions, []string{"v1.1.0", "v1.1.1"}) {
+		t.Errorf("unexpected result: %v %v", goVersions, err)
+	}
+	latest, err := mgr.LatestGoVer("cjson")
+	if err != nil || latest != "v2.0.0" {
+		t.Errorf("unexpected result: %v %v", latest, err)
+	}
+	if exists, err := mgr.ModuleExists("unknown"); exists || err != nil {
+		t.Errorf("unexpected result: %v %v", exists, err)
+	}
+	if _, err := mgr.CVerFromGoVer("cjson", "v9.9.9"); err == nil {
+		t.Error("unexpected behavior: unknown version is found")
+	}
+}
+
+func TestLayeredManager(t *testing.T) {
+	mgr, cleanup := setupTestEnv(t, enhancedTestVersionData)
+	defer cleanup()
+
+	override := MetadataMap{
+		"test-module": &Metadata{Versions: VersionMap{"1.7.18": {"v1.2.0"}}},
+	}
+	layered := NewLayeredManager(NewMemoryManager(override), NewMemoryManager(resolveTestData), mgr)
+
+	// the first layer wins
+	goVersions, err := layered.GoVersFromCVer("test-module", "1.7.18")
+	if err != nil || !reflect.DeepEqual(goVersions, []string{"v1.2.0"}) {
+		t.Errorf("unexpected result: %v %v", goVersions, err)
+	}
+	// fallback to the next layers
+	if cVersion, err := layered.CVerFromGoVer("test-module", "v1.4.1"); err != nil || cVersion != "1.8.0" {
+		t.Errorf("unexpected result: %v %v", cVersion, err)
+	}
+	if resolved, err := layered.Resolve("cjson@1.7"); err != nil || resolved.GoVersion != "v1.1.1" {
+		t.Errorf("unexpected result: %+v %v", resolved, err)
+	}
+	if exists, err := layered.ModuleExists("cjson"); !exists || err != nil {
+		t.Errorf("unexpected result: %v %v", exists, err)
+	}
+	if _, err := layered.MetadataByName("unknown"); !errors.Is(err, ErrMetadataNotInCache) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	all, err := layered.AllMetadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 || !reflect.DeepEqual(all["test-module"], override["test-module"]) {
+		t.Errorf("unexpected merged result: %v", all)
+	}
+}
@@ -3,10 +3,14 @@ package metadata
 import (
 	"errors"
 	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
 )
 
 var (
-	remoteMetadataURL      = "https://goplus.github.io/llpkg/llpkgstore.json" // change only for testing
+	remoteMetadataURL      = "https://goplus.github.io/llpkg/llpkgstore.json" // default remote URL, use WithBaseURL to change it
 	cachedMetadataFileName = "llpkgstore.json"
 	ErrMetadataNotInCache  = errors.New("metadata not in cache")
 )
@@ -18,32 +22,184 @@ type GoVersion = string
 type MetadataMap map[string]*Metadata
 
 type Metadata struct {
-	Versions map[CVersion][]GoVersion `json:"versions"`
+	Versions VersionMap `json:"versions"`
+	// Retracted maps the withdrawn Go versions to the reasons,
+	// they're never selected as the latest version.
+	Retracted map[GoVersion]string `json:"retracted,omitempty"`
+	// Releases maps the Go versions to their release details
+	Releases map[GoVersion]*Release `json:"releases,omitempty"`
+	PackageInfo
+}
+
+// Release holds the details of a published Go version.
+type Release struct {
+	Time   time.Time `json:"time"`   // publish time
+	Commit string    `json:"commit"` // SHA of the tagged commit
+	Assets []Asset   `json:"assets,omitempty"`
+}
+
+// Asset is a prebuilt binary package attached to the release.
+type Asset struct {
+	Name     string `json:"name"`               // file name, e.g. cjson_darwin_arm64.zip
+	Platform string `json:"platform,omitempty"` // GOOS/GOARCH, e.g. darwin/arm64
+	URL      string `json:"url"`                // download URL
+	SHA256   string `json:"sha256"`             // hex encoded SHA-256 checksum
 }
 
+// IsRetracted reports whether the Go version is retracted.
+func (m *Metadata) IsRetracted(goVersion GoVersion) bool {
+	_, ok := m.Retracted[goVersion]
+	return ok
+}
+
+// PackageInfo holds the optional descriptive fields of a package,
+// which are copied from llpkg.cfg for rendering package cards on the website.
+type PackageInfo struct {
+	Description string   `json:"description,omitempty"`
+	Homepage    string   `json:"homepage,omitempty"`
+	License     string   `json:"license,omitempty"`
+	Maintainers []string `json:"maintainers,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// metadataMgr is safe for concurrent use by multiple goroutines.
 type metadataMgr struct {
-	cache *Cache[MetadataMap]
+	source source
 
-	// Add flat hash for optimization
+	// Add flat hash for optimization.
+	// They're rebuilt instead of modified in place on update (copy-on-write),
+	// so a snapshot can be read without holding mu.
+	mu        sync.RWMutex
 	flatCToGo map[flatKey][]string // "name/cversion" -> []goversion
 	flatGoToC map[flatKey]string   // "name/goversion" -> cversion
+	flatNames map[string]flatNameIndex
+
+	// updates coalesces concurrent updates caused by cache misses into one
+	updates singleflight.Group
+	// rebuildMu serializes the rebuilds of the flat version maps, from reading
+	// the source to publishing them, so a newer rebuild is never overwritten by an older one.
+	rebuildMu sync.Mutex
+
+	settings settings
 }
 
-// NewMetadataMgr returns a new metadata manager
-func NewMetadataMgr(cacheDir string) (*metadataMgr, error) {
-	cachePath := filepath.Join(cacheDir, cachedMetadataFileName)
-	cache, err := NewCache[MetadataMap](cachePath, remoteMetadataURL)
-	if err != nil {
-		return nil, err
+// Manager looks up llpkg metadata, it's safe for concurrent use by multiple goroutines.
+type Manager interface {
+	// AllMetadata returns all up-to-date metadata
+	AllMetadata() (MetadataMap, error)
+	// MetadataByName returns the up-to-date module metadata by name
+	MetadataByName(name string) (Metadata, error)
+	// ModuleExists returns true if the name is an exist module name
+	ModuleExists(name string) (bool, error)
+
+	// LatestCVer returns the latest C version associated with the latest Go version
+	LatestCVer(name string) (string, error)
+	// LatestGoVer returns the latest Go version for the given module name
+	LatestGoVer(name string) (string, error)
+	// LatestGoVerFromCVer returns the latest Go version based on the module name and C version
+	LatestGoVerFromCVer(name, cVer string) (string, error)
+	// GoVersFromCVer returns Go versions based on the module name and C version
+	GoVersFromCVer(name, cVer string) ([]string, error)
+	// CVerFromGoVer returns the C version based on the module name and Go version
+	CVerFromGoVer(name, goVer string) (string, error)
+	// AllGoVersFromName returns all Go versions for the given module name
+	AllGoVersFromName(name string) ([]string, error)
+	// AllCVersFromName returns all C versions for the given module name
+	AllCVersFromName(name string) ([]string, error)
+
+	// Resolve resolves the version query, see the package function Resolve for the syntax
+	Resolve(query string) (Resolved, error)
+	// ReleaseInfo returns the release details of the Go version
+	ReleaseInfo(name, goVer string) (Release, error)
+}
+
+var _ Manager = (*metadataMgr)(nil)
+
+// flatNameIndex lists the versions of a module, to look up them without scanning the flat hash.
+type flatNameIndex struct {
+	cVersions  []string
+	goVersions []string
+}
+
+// source provides the metadata for metadataMgr.
+type source interface {
+	// Data returns the cached metadata
+	Data() MetadataMap
+	// Update refreshes the cached metadata of all modules
+	Update() error
+	// UpdateByName refreshes the cached metadata of the module,
+	// it's allowed to refresh more than that.
+	UpdateByName(name string) error
+}
+
+// fileSource is the source of llpkgstore.json, which is always refreshed as a whole.
+type fileSource struct {
+	*Cache[MetadataMap]
+}
+
+func (s fileSource) UpdateByName(string) error {
+	return s.Update()
+}
+
+// NewMetadataMgr returns a new metadata manager,
+// which fetches metadata from the remote store and caches it in cacheDir.
+func NewMetadataMgr(cacheDir string, opts ...Options) (Manager, error) {
+	return newMetadataMgr(cacheDir, opts...)
+}
+
+// NewMemoryManager returns a metadata manager serving the fixed metadata map,
+// which is useful for tests and tools injecting fixtures.
+func NewMemoryManager(m MetadataMap) Manager {
+	mgr := &metadataMgr{source: memorySource(m)}
+	mgr.buildFlatVersionMaps()
+	return mgr
+}
+
+// memorySource is a source never changes.
+type memorySource MetadataMap
+
+func (s memorySource) Data() MetadataMap {
+	return MetadataMap(s)
+}
+
+func (s memorySource) Update() error {
+	return nil
+}
+
+func (s memorySource) UpdateByName(string) error {
+	return nil
+}
+
+func newMetadataMgr(cacheDir string, opts ...Options) (*metadataMgr, error) {
+	settings := newSettings(opts)
+
+	var src source
+	if settings.sharded {
+		sharded, err := newShardedSource(cacheDir, opts...)
+		if err != nil {
+			return nil, err
+		}
+		src = sharded
+	} else {
+		remoteURL := remoteMetadataURL
+		if settings.baseURL != "" {
+			remoteURL = joinURL(settings.baseURL, cachedMetadataFileName)
+		}
+
+		cachePath := filepath.Join(cacheDir, cachedMetadataFileName)
+		cache, err := NewCache[MetadataMap](cachePath, remoteURL, opts...)
+		if err != nil {
+			return nil, err
+		}
+		src = fileSource{cache}
 	}
 
 	mgr := &metadataMgr{
-		cache:     cache,
-		flatCToGo: make(map[flatKey][]string),
-		flatGoToC: make(map[flatKey]string),
+		settings: settings,
+		source:   src,
 	}
 
-	err = mgr.buildFlatVersionMaps()
+	err := mgr.buildFlatVersionMaps()
 	if err != nil {
 		return nil, err
 	}
@@ -51,11 +207,13 @@ func NewMetadataMgr(cacheDir string) (*metadataMgr, error) {
 	return mgr, nil
 }
 
-// Returns all up-to-date metadata
+// Returns all up-to-date metadata, or all cached metadata in offline mode
 func (m *metadataMgr) AllMetadata() (MetadataMap, error) {
-	err := m.update()
-	if err != nil {
-		return nil, err
+	if !m.settings.offline {
+		err := m.update()
+		if err != nil {
+			return nil, err
+		}
 	}
 	return m.allCachedMetadata(), nil
 }
@@ -66,7 +224,7 @@ func (m *metadataMgr) MetadataByName(name string) (Metadata, error) {
 	metadata, err := m.cachedMetadataByName(name)
 	if errors.Is(err, ErrMetadataNotInCache) || errors.Is(err, ErrCacheFileNotFound) {
 		// If the module metadata is not in the cache, update the cache
-		err := m.update()
+		err := m.updateByName(name)
 		if err != nil {
 			return Metadata{}, err
 		}
@@ -94,8 +252,7 @@ func (m *metadataMgr) ModuleExists(name string) (bool, error) {
 
 // Returns the module metadata in the cache
 func (m *metadataMgr) allCachedMetadata() MetadataMap {
-	cache := m.cache.Data()
-	return cache
+	return m.source.Data()
 }
 
 // Returns the module metadata in the cache by name
@@ -103,47 +260,102 @@ func (m *metadataMgr) cachedMetadataByName(name string) (Metadata, error) {
 	allMetadata := m.allCachedMetadata()
 
 	metadata, ok := allMetadata[name]
-	if !ok {
+	if !ok || metadata == nil {
 		return Metadata{}, ErrMetadataNotInCache
 	}
 
 	return *metadata, nil
 }
 
+// update refreshes the cache and the flat version maps,
+// concurrent calls share the result of the one in flight.
 func (m *metadataMgr) update() error {
-	err := m.cache.Update()
-	if err != nil {
-		return err
-	}
+	_, err, _ := m.updates.Do("update", func() (any, error) {
+		err := m.source.Update()
+		if err != nil {
+			return nil, err
+		}
 
-	err = m.buildFlatVersionMaps()
-	if err != nil {
-		return err
-	}
+		err = m.buildFlatVersionMaps()
+		if err != nil {
+			return nil, err
+		}
 
-	return nil
+		return nil, nil
+	})
+	return err
+}
+
+// updateByName refreshes the cache of the module and the flat version maps,
+// concurrent calls for the same module share the result of the one in flight.
+func (m *metadataMgr) updateByName(name string) error {
+	_, err, _ := m.updates.Do("update/"+name, func() (any, error) {
+		err := m.source.UpdateByName(name)
+		if err != nil {
+			return nil, err
+		}
+
+		err = m.buildFlatVersionMaps()
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, nil
+	})
+	return err
 }
 
 func (m *metadataMgr) buildFlatVersionMaps() error {
-	// Reset flat hash
-	m.flatCToGo = make(map[flatKey][]string)
-	m.flatGoToC = make(map[flatKey]string)
+	m.rebuildMu.Lock()
+	defer m.rebuildMu.Unlock()
+
+	// Build new flat hash, the old one may be in use by readers
+	flatCToGo := make(map[flatKey][]string)
+	flatGoToC := make(map[flatKey]string)
+	flatNames := make(map[string]flatNameIndex)
 
 	allCachedMetadata := m.allCachedMetadata()
 
 	for name, metadata := range allCachedMetadata {
+		if metadata == nil {
+			continue
+		}
+		var nameIndex flatNameIndex
 		versions := metadata.Versions
 		for cVersion, goVersions := range versions {
 			// Build flat hash
 			cKey := flatKey{name, cVersion}
-			m.flatCToGo[cKey] = goVersions
+			flatCToGo[cKey] = goVersions
+			nameIndex.cVersions = append(nameIndex.cVersions, cVersion)
 
 			for _, goVersion := range goVersions {
 				goKey := flatKey{name, goVersion}
-				m.flatGoToC[goKey] = cVersion
+				flatGoToC[goKey] = cVersion
+				nameIndex.goVersions = append(nameIndex.goVersions, goVersion)
 			}
 		}
+		flatNames[name] = nameIndex
 	}
 
+	m.mu.Lock()
+	m.flatCToGo, m.flatGoToC, m.flatNames = flatCToGo, flatGoToC, flatNames
+	m.mu.Unlock()
+
 	return nil
 }
+
+// flatVersionMaps returns a snapshot of the flat version maps,
+// which must not be modified.
+func (m *metadataMgr) flatVersionMaps() (flatCToGo map[flatKey][]string, flatGoToC map[flatKey]string) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.flatCToGo, m.flatGoToC
+}
+
+// flatNameIndex returns the versions of the module in the flat hash, which must not be modified.
+func (m *metadataMgr) flatNameIndex(name string) (flatNameIndex, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	nameIndex, ok := m.flatNames[name]
+	return nameIndex, ok
+}
@@ -8,7 +8,10 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 var testMetadata = MetadataMap{
@@ -45,7 +48,7 @@ func TestNewMetadataMgr(t *testing.T) {
 	// Verify successful initialization
 	if mgr == nil {
 		t.Fatal("Metadata manager should not be nil")
-	} else if mgr.cache == nil {
+	} else if mgr.(*metadataMgr).source == nil {
 		t.Fatal("Metadata cache should not be nil")
 	}
 }
@@ -67,7 +70,7 @@ func TestMetadataMgr_AllMetadata(t *testing.T) {
 	remoteMetadataURL = server.URL + "/llpkgstore.json"
 
 	tmpDir := t.TempDir()
-	mgr, _ := NewMetadataMgr(tmpDir)
+	mgr, _ := newMetadataMgr(tmpDir)
 
 	// Force metadata update from mock server
 	err := mgr.update()
@@ -104,7 +107,7 @@ func TestNewMetadataMgr_LocalAndRemoteFailure(t *testing.T) {
 	defer func() { remoteMetadataURL = originalURL }()
 	remoteMetadataURL = server.URL + "/llpkgstore.json"
 
-	_, err = NewMetadataMgr(tmpDir)
+	_, err = newMetadataMgr(tmpDir)
 	if err == nil {
 		t.Fatal("Expected error, but got nil")
 	}
@@ -126,7 +129,7 @@ func TestMetadataMgr_MetadataByName_Existing(t *testing.T) {
 	remoteMetadataURL = server.URL + "/llpkgstore.json"
 
 	tmpDir := t.TempDir()
-	mgr, _ := NewMetadataMgr(tmpDir)
+	mgr, _ := newMetadataMgr(tmpDir)
 
 	metadata, err := mgr.MetadataByName("example-module")
 	if err != nil {
@@ -154,7 +157,7 @@ func TestMetadataMgr_MetadataByName_NotFound(t *testing.T) {
 	remoteMetadataURL = server.URL + "/llpkgstore.json"
 
 	tmpDir := t.TempDir()
-	mgr, _ := NewMetadataMgr(tmpDir)
+	mgr, _ := newMetadataMgr(tmpDir)
 
 	_, err := mgr.MetadataByName("nonexistent-module")
 	if !errors.Is(err, ErrMetadataNotInCache) {
@@ -178,7 +181,7 @@ func TestMetadataMgr_ModuleExists(t *testing.T) {
 	remoteMetadataURL = server.URL + "/llpkgstore.json"
 
 	tmpDir := t.TempDir()
-	mgr, _ := NewMetadataMgr(tmpDir)
+	mgr, _ := newMetadataMgr(tmpDir)
 
 	exists, err := mgr.ModuleExists("example-module")
 	if err != nil {
@@ -220,7 +223,7 @@ func TestMetadataMgr_UpdateError(t *testing.T) {
 	tmpDir := t.TempDir()
 	// ensure metadataMgr can be created
 	os.WriteFile(filepath.Join(tmpDir, "llpkgstore.json"), testMetadataJSON, 0644)
-	mgr, err := NewMetadataMgr(tmpDir)
+	mgr, err := newMetadataMgr(tmpDir)
 	if err != nil {
 		t.Fatalf("Failed to create metadata manager: %v", err)
 	}
@@ -249,7 +252,7 @@ func TestNewMetadataMgr_InvalidRemoteData(t *testing.T) {
 	remoteMetadataURL = server.URL + "/llpkgstore.json"
 
 	tmpDir := t.TempDir()
-	_, err := NewMetadataMgr(tmpDir)
+	_, err := newMetadataMgr(tmpDir)
 	if err == nil {
 		t.Fatal("Expected error, but got nil")
 	}
@@ -278,7 +281,106 @@ func TestNewMetadataMgr_BadCacheFile(t *testing.T) {
 	defer func() { remoteMetadataURL = originalURL }()
 	remoteMetadataURL = server.URL + "/llpkgstore.json"
 
-	mgr, err := NewMetadataMgr(tmpDir)
+	mgr, err := newMetadataMgr(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create metadata manager: %v", err)
+	}
+
+	data, err := mgr.AllMetadata()
+	if err != nil {
+		t.Fatalf("Failed to retrieve metadata: %v", err)
+	}
+	if !reflect.DeepEqual(data, testMetadata) {
+		t.Errorf("Metadata mismatch. Expected: %v, Got: %v", testMetadata, data)
+	}
+}
+
+// TestMetadataMgr_Offline verifies offline mode serves from the on-disk cache only
+func TestMetadataMgr_Offline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected network access in offline mode: %s", r.URL)
+	}))
+	defer server.Close()
+
+	originalURL := remoteMetadataURL
+	defer func() { remoteMetadataURL = originalURL }()
+	remoteMetadataURL = server.URL + "/llpkgstore.json"
+
+	tmpDir := t.TempDir()
+
+	// no cache file
+	_, err := NewMetadataMgr(tmpDir, WithOffline())
+	if !errors.Is(err, ErrOffline) {
+		t.Fatalf("Expected ErrOffline, but got: %v", err)
+	}
+
+	testMetadataJSON, err := json.Marshal(testMetadata)
+	if err != nil {
+		t.Fatalf("Failed to marshal test metadata: %v", err)
+	}
+	os.WriteFile(filepath.Join(tmpDir, cachedMetadataFileName), testMetadataJSON, 0644)
+
+	mgr, err := newMetadataMgr(tmpDir, WithOffline())
+	if err != nil {
+		t.Fatalf("Failed to create metadata manager: %v", err)
+	}
+
+	data, err := mgr.AllMetadata()
+	if err != nil {
+		t.Fatalf("Failed to retrieve metadata: %v", err)
+	}
+	if !reflect.DeepEqual(data, testMetadata) {
+		t.Errorf("Metadata mismatch. Expected: %v, Got: %v", testMetadata, data)
+	}
+
+	goVer, err := mgr.LatestGoVerFromCVer("example-module", "1.7.18")
+	if err != nil || goVer != "v1.2.0" {
+		t.Errorf("Unexpected result: %s %v", goVer, err)
+	}
+
+	_, err = mgr.MetadataByName("nonexistent-module")
+	if !errors.Is(err, ErrOffline) {
+		t.Errorf("Expected ErrOffline, but got: %v", err)
+	}
+	_, err = mgr.CVerFromGoVer("example-module", "v9.9.9")
+	if !errors.Is(err, ErrOffline) {
+		t.Errorf("Expected ErrOffline, but got: %v", err)
+	}
+}
+
+type headerTransport struct {
+	header http.Header
+}
+
+func (h *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range h.header {
+		req.Header[k] = v
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// TestNewMetadataMgr_BaseURLAndClient verifies the store URL and HTTP client are configurable
+func TestNewMetadataMgr_BaseURLAndClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/private/llpkgstore.json" {
+			json.NewEncoder(w).Encode(testMetadata)
+		} else {
+			http.Error(w, "Not Found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &headerTransport{header: http.Header{"Authorization": {"Bearer token"}}},
+	}
+
+	tmpDir := t.TempDir()
+	mgr, err := newMetadataMgr(tmpDir, WithBaseURL(server.URL+"/private/"), WithHTTPClient(client))
 	if err != nil {
 		t.Fatalf("Failed to create metadata manager: %v", err)
 	}
@@ -290,4 +392,98 @@ func TestNewMetadataMgr_BadCacheFile(t *testing.T) {
 	if !reflect.DeepEqual(data, testMetadata) {
 		t.Errorf("Metadata mismatch. Expected: %v, Got: %v", testMetadata, data)
 	}
+
+	// without auth header
+	_, err = NewMetadataMgr(t.TempDir(), WithBaseURL(server.URL+"/private"))
+	if err == nil {
+		t.Fatal("Expected error, but got nil")
+	}
+}
+
+// TestMetadataMgr_Concurrent verifies that the manager is safe for concurrent use,
+// and concurrent cache misses are coalesced into one update.
+func TestMetadataMgr_Concurrent(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) > 1 {
+			// let concurrent misses pile up
+			time.Sleep(100 * time.Millisecond)
+		}
+		json.NewEncoder(w).Encode(testMetadata)
+	}))
+	defer server.Close()
+
+	mgr, err := newMetadataMgr(t.TempDir(), WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("Failed to create metadata manager: %v", err)
+	}
+
+	const workers = 16
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := mgr.LatestGoVerFromCVer("example-module", "1.7.18"); err != nil {
+				t.Error(err)
+			}
+			if _, err := mgr.CVerFromGoVer("example-module", "v1.2.0"); err != nil {
+				t.Error(err)
+			}
+			// a miss triggers an update
+			if _, err := mgr.GoVersFromCVer("example-module", "0.0.0"); err == nil {
+				t.Error("unexpected mapping for 0.0.0")
+			}
+		}()
+	}
+	wg.Wait()
+
+	// one initial request, and far fewer updates than misses
+	if n := requests.Load(); n > workers/2 {
+		t.Errorf("concurrent updates are not coalesced: %d requests", n)
+	}
+}
+
+// slowSource returns the metadata of its current generation,
+// the read of the first generation is slow.
+type slowSource struct {
+	gen atomic.Int32
+}
+
+func (s *slowSource) Data() MetadataMap {
+	gen := s.gen.Load()
+	if gen == 1 {
+		time.Sleep(100 * time.Millisecond)
+	}
+	return MetadataMap{"cjson": &Metadata{Versions: VersionMap{"1.7.18": {"v1.0." + string(rune('0'+gen))}}}}
+}
+
+func (s *slowSource) Update() error {
+	s.gen.Add(1)
+	return nil
+}
+
+func (s *slowSource) UpdateByName(string) error {
+	return s.Update()
+}
+
+// Test a slow rebuild from older data never overwrites a newer one
+func TestMetadataMgr_SerializedRebuild(t *testing.T) {
+	mgr := &metadataMgr{source: &slowSource{}}
+	done := make(chan error)
+	go func() {
+		done <- mgr.update()
+	}()
+	// the rebuild of the first generation is reading the source
+	time.Sleep(20 * time.Millisecond)
+	if err := mgr.updateByName("cjson"); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if cVersion, err := mgr.CVerFromGoVer("cjson", "v1.0.2"); err != nil || cVersion != "1.7.18" {
+		t.Errorf("unexpected result: rebuilt from stale data: %s %v", cVersion, err)
+	}
 }
@@ -0,0 +1,107 @@
+package metadata
+
+import (
+	"crypto/ed25519"
+	"net/http"
+	"strings"
+)
+
+// Options configures the metadata manager and its cache.
+type Options func(*settings)
+
+type settings struct {
+	offline bool         // serve from the on-disk cache only
+	baseURL string       // base URL of the store
+	mirrors []string     // base URLs of the mirrors, tried in order on failure
+	client  *http.Client // HTTP client for fetching remote data
+
+	publicKeys []ed25519.PublicKey // keys to verify the signature of remote data
+	sharded    bool                // fetch the sharded layout instead of llpkgstore.json
+
+	verify func(body []byte) error // extra verification of remote data
+}
+
+func newSettings(opts []Options) settings {
+	var s settings
+	for _, o := range opts {
+		o(&s)
+	}
+	return s
+}
+
+// httpClient returns the configured HTTP client, http.DefaultClient by default.
+func (s *settings) httpClient() *http.Client {
+	if s.client != nil {
+		return s.client
+	}
+	return http.DefaultClient
+}
+
+// WithOffline serves metadata from the on-disk cache only, and never touches the network.
+// Lookups return ErrOffline when the data is missing from the cache.
+func WithOffline() Options {
+	return func(s *settings) {
+		s.offline = true
+	}
+}
+
+// WithBaseURL sets the base URL of the store, e.g. https://goplus.github.io/llpkg,
+// llpkgstore.json is fetched from {baseURL}/llpkgstore.json.
+func WithBaseURL(baseURL string) Options {
+	return func(s *settings) {
+		s.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithMirrors sets the base URLs of the store mirrors.
+// When fetching from the store fails, the mirrors are tried in order.
+func WithMirrors(baseURLs ...string) Options {
+	return func(s *settings) {
+		for _, baseURL := range baseURLs {
+			s.mirrors = append(s.mirrors, strings.TrimSuffix(baseURL, "/"))
+		}
+	}
+}
+
+// WithHTTPClient sets the HTTP client for fetching remote data,
+// which allows to configure proxies, TLS roots and auth headers.
+func WithHTTPClient(client *http.Client) Options {
+	return func(s *settings) {
+		s.client = client
+	}
+}
+
+// WithPublicKeys requires the remote data to be signed by any of the keys.
+// Data without a valid detached signature is refused, and the last good copy is kept.
+func WithPublicKeys(keys ...ed25519.PublicKey) Options {
+	return func(s *settings) {
+		s.publicKeys = append(s.publicKeys, keys...)
+	}
+}
+
+// WithSharding fetches the sharded layout of the store, index.json and packages/{name}.json,
+// instead of llpkgstore.json, only the packages being looked up are fetched.
+func WithSharding() Options {
+	return func(s *settings) {
+		s.sharded = true
+	}
+}
+
+// withVerifier verifies the remote data with verify before accepting it.
+func withVerifier(verify func(body []byte) error) Options {
+	return func(s *settings) {
+		s.verify = verify
+	}
+}
+
+// withoutSignature skips the signature verification, when the data is verified in other ways.
+func withoutSignature() Options {
+	return func(s *settings) {
+		s.publicKeys = nil
+	}
+}
+
+// joinURL joins a base URL and a path relative to it.
+func joinURL(baseURL, path string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/" + strings.TrimPrefix(path, "/")
+}
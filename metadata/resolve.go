@@ -0,0 +1,249 @@
+package metadata
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// ModulePathPrefix is the module path prefix of llpkgs.
+const ModulePathPrefix = "github.com/goplus/llpkg/"
+
+var ErrNoMatchingVersion = errors.New("no matching version")
+
+// Resolved is the result of resolving a version query.
+type Resolved struct {
+	Name       string    // name of the C library, e.g. cjson
+	ModulePath string    // module path of the llpkg, e.g. github.com/goplus/llpkg/cjson
+	GoVersion  GoVersion // mapped version of the llpkg, e.g. v1.1.0
+	CVersion   CVersion  // version of the C library, e.g. 1.7.18
+}
+
+// Resolve resolves a version query against the metadata map.
+//
+// The query is either clib@cversion, where cversion can be:
+//
+//	cjson              the latest version, same as cjson@latest
+//	cjson@1.7.18       the exact C version
+//	cjson@1.7          the latest C version with the prefix
+//	cjson@>=1.7 <2     the latest C version in the range, comparators are ANDed
+//
+// or module@version, where version is an exact mapped version, latest,
+// or a prefix of it like v1.1:
+//
+//	github.com/goplus/llpkg/cjson@v1.1.0
+//
+// The latest mapped version of the selected C version is returned.
+// Retracted versions are skipped, unless the exact mapped version is queried.
+func Resolve(m MetadataMap, query string) (Resolved, error) {
+	name, version, _ := strings.Cut(strings.TrimSpace(query), "@")
+	if strings.HasPrefix(name, ModulePathPrefix) {
+		return resolveModule(m, name, version)
+	}
+	if strings.Contains(name, "/") {
+		return Resolved{}, fmt.Errorf("invalid query %s: module path must start with %s", query, ModulePathPrefix)
+	}
+
+	metadata, ok := m[name]
+	if !ok || metadata == nil {
+		return Resolved{}, fmt.Errorf("%s: %w", name, ErrMetadataNotInCache)
+	}
+	version = strings.TrimSpace(version)
+
+	var cVersion CVersion
+	switch {
+	case version == "" || version == "latest":
+		goVersion := latestGoVersion(metadata, func(CVersion, GoVersion) bool { return true })
+		if goVersion == "" {
+			return Resolved{}, fmt.Errorf("%s: %w", query, ErrNoMatchingVersion)
+		}
+		return newResolved(name, goVersion, cVersionOf(metadata.Versions, goVersion)), nil
+	case metadata.Versions[version] != nil:
+		cVersion = version
+	case strings.ContainsAny(version, "<>=!, "):
+		constraint, err := parseConstraint(version)
+		if err != nil {
+			return Resolved{}, fmt.Errorf("invalid query %s: %w", query, err)
+		}
+		cVersion = latestCVersion(metadata, constraint)
+	default:
+		cVersion = latestCVersion(metadata, func(c CVersion) bool {
+			return strings.HasPrefix(c, version+".")
+		})
+	}
+
+	goVersion := latestGoVersion(metadata, func(c CVersion, _ GoVersion) bool { return c == cVersion })
+	if cVersion == "" || goVersion == "" {
+		return Resolved{}, fmt.Errorf("%s: %w", query, ErrNoMatchingVersion)
+	}
+	return newResolved(name, goVersion, cVersion), nil
+}
+
+// queryName returns the name of the C library in the query.
+func queryName(query string) string {
+	name, _, _ := strings.Cut(strings.TrimSpace(query), "@")
+	name = strings.TrimPrefix(name, ModulePathPrefix)
+	name, _, _ = strings.Cut(name, "/")
+	return name
+}
+
+// resolveModule resolves module@version, module path of major version 2 or higher
+// must have the major version suffix, like github.com/goplus/llpkg/cjson/v2.
+func resolveModule(m MetadataMap, modulePath, version string) (Resolved, error) {
+	name := strings.TrimPrefix(modulePath, ModulePathPrefix)
+	major := ""
+	if base, suffix, ok := strings.Cut(name, "/"); ok {
+		n, err := strconv.Atoi(strings.TrimPrefix(suffix, "v"))
+		if !strings.HasPrefix(suffix, "v") || err != nil || n < 2 || suffix != fmt.Sprintf("v%d", n) {
+			return Resolved{}, fmt.Errorf("invalid module path: %s", modulePath)
+		}
+		name, major = base, suffix
+	}
+
+	metadata, ok := m[name]
+	if !ok || metadata == nil {
+		return Resolved{}, fmt.Errorf("%s: %w", modulePath, ErrMetadataNotInCache)
+	}
+
+	var goVersion GoVersion
+	if semver.IsValid(version) && semver.Canonical(version) == version {
+		// the exact version is resolved even if it's retracted
+		if majorSuffix(version) == major && cVersionOf(metadata.Versions, version) != "" {
+			goVersion = version
+		}
+	} else {
+		goVersion = latestGoVersion(metadata, func(_ CVersion, goVersion GoVersion) bool {
+			if majorSuffix(goVersion) != major {
+				return false
+			}
+			// a prefix like v1 or v1.1
+			return version == "" || version == "latest" ||
+				(semver.IsValid(version) && strings.HasPrefix(goVersion, version+"."))
+		})
+	}
+	if goVersion == "" {
+		return Resolved{}, fmt.Errorf("%s@%s: %w", modulePath, version, ErrNoMatchingVersion)
+	}
+	return newResolved(name, goVersion, cVersionOf(metadata.Versions, goVersion)), nil
+}
+
+func newResolved(name string, goVersion GoVersion, cVersion CVersion) Resolved {
+	modulePath := ModulePathPrefix + name
+	if major := majorSuffix(goVersion); major != "" {
+		modulePath += "/" + major
+	}
+	return Resolved{
+		Name:       name,
+		ModulePath: modulePath,
+		GoVersion:  goVersion,
+		CVersion:   cVersion,
+	}
+}
+
+// majorSuffix returns the major version suffix of the module path for the Go version,
+// v0 and v1 share the module path without suffix.
+func majorSuffix(goVersion GoVersion) string {
+	major := semver.Major(goVersion)
+	if major == "v0" || major == "v1" {
+		return ""
+	}
+	return major
+}
+
+// latestCVersion returns the latest C version satisfying match, or empty if none.
+// C versions whose Go versions are all retracted are skipped.
+func latestCVersion(metadata *Metadata, match func(CVersion) bool) (latest CVersion) {
+	for c, goVersions := range metadata.Versions {
+		if !slices.ContainsFunc(goVersions, func(v GoVersion) bool { return !metadata.IsRetracted(v) }) {
+			continue
+		}
+		if match(c) && (latest == "" || compareCVersion(c, latest) > 0) {
+			latest = c
+		}
+	}
+	return
+}
+
+// latestGoVersion returns the latest Go version satisfying match, or empty if none.
+// Retracted versions are skipped.
+func latestGoVersion(metadata *Metadata, match func(CVersion, GoVersion) bool) (latest GoVersion) {
+	for c, goVersions := range metadata.Versions {
+		for _, goVersion := range goVersions {
+			if !metadata.IsRetracted(goVersion) && match(c, goVersion) && (latest == "" || semver.Compare(goVersion, latest) > 0) {
+				latest = goVersion
+			}
+		}
+	}
+	return
+}
+
+// cVersionOf returns the C version mapped to the Go version.
+func cVersionOf(versions VersionMap, goVersion GoVersion) CVersion {
+	for c, goVersions := range versions {
+		for _, v := range goVersions {
+			if v == goVersion {
+				return c
+			}
+		}
+	}
+	return ""
+}
+
+// parseConstraint parses comparators like ">=1.7 <2" or ">= 1.7, != 1.7.3",
+// a version without operator means equal.
+func parseConstraint(s string) (func(CVersion) bool, error) {
+	var comparators []func(CVersion) bool
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' })
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		version := strings.TrimLeft(field, "<>=!")
+		op := field[:len(field)-len(version)]
+		if version == "" && i+1 < len(fields) {
+			// the operator is separated from the version by spaces
+			i++
+			version = fields[i]
+		}
+		bound := "v" + version
+		if !semver.IsValid(bound) {
+			return nil, fmt.Errorf("invalid version in constraint: %q", version)
+		}
+
+		var cmp func(int) bool
+		switch op {
+		case "", "=", "==":
+			cmp = func(c int) bool { return c == 0 }
+		case "!=":
+			cmp = func(c int) bool { return c != 0 }
+		case ">":
+			cmp = func(c int) bool { return c > 0 }
+		case ">=":
+			cmp = func(c int) bool { return c >= 0 }
+		case "<":
+			cmp = func(c int) bool { return c < 0 }
+		case "<=":
+			cmp = func(c int) bool { return c <= 0 }
+		default:
+			return nil, fmt.Errorf("invalid operator in constraint: %q", op)
+		}
+		comparators = append(comparators, func(c CVersion) bool {
+			v := "v" + c
+			return semver.IsValid(v) && cmp(semver.Compare(v, bound))
+		})
+	}
+	if len(comparators) == 0 {
+		return nil, errors.New("empty constraint")
+	}
+
+	return func(c CVersion) bool {
+		for _, comparator := range comparators {
+			if !comparator(c) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
@@ -0,0 +1,81 @@
+package metadata
+
+import (
+	"errors"
+	"testing"
+)
+
+var resolveTestData = MetadataMap{
+	"cjson": &Metadata{
+		Versions: VersionMap{
+			"1.7.17": {"v1.0.0"},
+			"1.7.18": {"v1.1.0", "v1.1.1"},
+			"1.8.0":  {"v1.2.0"},
+			"2.0.0":  {"v2.0.0"},
+		},
+	},
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected Resolved
+	}{
+		{"cjson", Resolved{"cjson", "github.com/goplus/llpkg/cjson/v2", "v2.0.0", "2.0.0"}},
+		{"cjson@latest", Resolved{"cjson", "github.com/goplus/llpkg/cjson/v2", "v2.0.0", "2.0.0"}},
+		{"cjson@1.7.18", Resolved{"cjson", "github.com/goplus/llpkg/cjson", "v1.1.1", "1.7.18"}},
+		{"cjson@1.7", Resolved{"cjson", "github.com/goplus/llpkg/cjson", "v1.1.1", "1.7.18"}},
+		{"cjson@1", Resolved{"cjson", "github.com/goplus/llpkg/cjson", "v1.2.0", "1.8.0"}},
+		{"cjson@>=1.7 <2", Resolved{"cjson", "github.com/goplus/llpkg/cjson", "v1.2.0", "1.8.0"}},
+		{"cjson@>= 1.7, < 1.8, != 1.7.18", Resolved{"cjson", "github.com/goplus/llpkg/cjson", "v1.0.0", "1.7.17"}},
+		{"github.com/goplus/llpkg/cjson@v1.1.0", Resolved{"cjson", "github.com/goplus/llpkg/cjson", "v1.1.0", "1.7.18"}},
+		{"github.com/goplus/llpkg/cjson@v1.1", Resolved{"cjson", "github.com/goplus/llpkg/cjson", "v1.1.1", "1.7.18"}},
+		{"github.com/goplus/llpkg/cjson", Resolved{"cjson", "github.com/goplus/llpkg/cjson", "v1.2.0", "1.8.0"}},
+		{"github.com/goplus/llpkg/cjson/v2@latest", Resolved{"cjson", "github.com/goplus/llpkg/cjson/v2", "v2.0.0", "2.0.0"}},
+	}
+	for _, tc := range tests {
+		resolved, err := Resolve(resolveTestData, tc.query)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.query, err)
+			continue
+		}
+		if resolved != tc.expected {
+			t.Errorf("%s: want %+v got %+v", tc.query, tc.expected, resolved)
+		}
+	}
+}
+
+func TestResolveError(t *testing.T) {
+	tests := map[string]error{
+		"unknown":                                 ErrMetadataNotInCache,
+		"cjson@1.9":                               ErrNoMatchingVersion,
+		"cjson@>3":                                ErrNoMatchingVersion,
+		"github.com/goplus/llpkg/cjson@v1.1.2":    ErrNoMatchingVersion,
+		"github.com/goplus/llpkg/cjson@v2.0.0":    ErrNoMatchingVersion,
+		"github.com/goplus/llpkg/cjson/v1@v1.0.0": nil,
+		"github.com/other/cjson@v1.0.0":           nil,
+		"cjson@>=abc":                             nil,
+	}
+	for query, expected := range tests {
+		_, err := Resolve(resolveTestData, query)
+		if err == nil || (expected != nil && !errors.Is(err, expected)) {
+			t.Errorf("%s: unexpected error: %v", query, err)
+		}
+	}
+}
+
+func TestMetadataMgr_Resolve(t *testing.T) {
+	mgr, cleanup := setupTestEnv(t, enhancedTestVersionData)
+	defer cleanup()
+
+	resolved, err := mgr.Resolve("test-module@1.7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.GoVersion != "v1.3.0" || resolved.CVersion != "1.7.19" {
+		t.Errorf("unexpected result: %+v", resolved)
+	}
+	if _, err := mgr.Resolve("test-module@3"); !errors.Is(err, ErrNoMatchingVersion) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package metadata
+
+import (
+	"sort"
+	"strings"
+)
+
+// Scores of a query word matching a package, the best one of a word is taken.
+const (
+	scoreNameExact     = 100
+	scoreNamePrefix    = 80
+	scoreNameSubstring = 60
+	scoreTagExact      = 50
+	scoreTagSubstring  = 40
+	scoreDescription   = 30
+	scoreNameFuzzy     = 20
+)
+
+// SearchResult is a package matching the search query.
+type SearchResult struct {
+	Name  string
+	Score int // relevance, higher is better
+}
+
+// Search looks up packages by the query, ranked by relevance.
+//
+// The query is split into words, which are matched case-insensitively against
+// the package name by exact, prefix, substring and fuzzy (subsequence) matching,
+// and against the tags and the description. Every word must match,
+// the score of a package is the sum of the best scores of the words.
+//
+// Results are ordered by score, then by the length of the name and the name.
+// All packages are returned in that order if the query is empty.
+//
+// The website search in internal/site/static/search.js is implemented
+// in the same way, keep them in sync.
+func Search(m MetadataMap, query string) []SearchResult {
+	words := strings.Fields(strings.ToLower(query))
+
+	var results []SearchResult
+	for name, metadata := range m {
+		score := 0
+		for _, word := range words {
+			wordScore := matchScore(strings.ToLower(name), metadata, word)
+			if wordScore == 0 {
+				score = 0
+				break
+			}
+			score += wordScore
+		}
+		if score > 0 || len(words) == 0 {
+			results = append(results, SearchResult{Name: name, Score: score})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if len(a.Name) != len(b.Name) {
+			return len(a.Name) < len(b.Name)
+		}
+		return a.Name < b.Name
+	})
+	return results
+}
+
+// matchScore returns the best score of the word matching the package, zero if not matched.
+func matchScore(name string, metadata *Metadata, word string) int {
+	switch {
+	case name == word:
+		return scoreNameExact
+	case strings.HasPrefix(name, word):
+		return scoreNamePrefix
+	case strings.Contains(name, word):
+		return scoreNameSubstring
+	}
+
+	best := 0
+	if metadata != nil {
+		for _, tag := range metadata.Tags {
+			tag = strings.ToLower(tag)
+			if tag == word {
+				return scoreTagExact
+			}
+			if strings.Contains(tag, word) {
+				best = scoreTagSubstring
+			}
+		}
+		if best == 0 && strings.Contains(strings.ToLower(metadata.Description), word) {
+			best = scoreDescription
+		}
+	}
+	if best == 0 && isSubsequence(word, name) {
+		best = scoreNameFuzzy
+	}
+	return best
+}
+
+// isSubsequence reports whether the characters of sub appear in s in order.
+func isSubsequence(sub, s string) bool {
+	i := 0
+	for j := 0; i < len(sub) && j < len(s); j++ {
+		if sub[i] == s[j] {
+			i++
+		}
+	}
+	return i == len(sub)
+}
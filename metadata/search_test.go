@@ -0,0 +1,48 @@
+package metadata
+
+import (
+	"reflect"
+	"testing"
+)
+
+var searchTestData = MetadataMap{
+	"cjson": &Metadata{
+		PackageInfo: PackageInfo{Description: "Ultralightweight JSON parser in ANSI C", Tags: []string{"json", "parser"}},
+	},
+	"libxml2":  &Metadata{PackageInfo: PackageInfo{Description: "XML parser and toolkit", Tags: []string{"xml"}}},
+	"json-c":   &Metadata{PackageInfo: PackageInfo{Tags: []string{"json"}}},
+	"zlib":     &Metadata{PackageInfo: PackageInfo{Description: "A massively spiffy yet delicately unobtrusive compression library"}},
+	"sqlite3":  &Metadata{},
+	"jsoncons": nil,
+}
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected []SearchResult
+	}{
+		{"json", []SearchResult{
+			{"json-c", scoreNamePrefix},
+			{"jsoncons", scoreNamePrefix},
+			{"cjson", scoreNameSubstring},
+		}},
+		{"CJSON", []SearchResult{{"cjson", scoreNameExact}}},
+		{"parser", []SearchResult{
+			{"cjson", scoreTagExact},
+			{"libxml2", scoreDescription},
+		}},
+		{"xml parser", []SearchResult{{"libxml2", scoreNameSubstring + scoreDescription}}},
+		{"sqlt", []SearchResult{{"sqlite3", scoreNameFuzzy}}},
+		{"compression", []SearchResult{{"zlib", scoreDescription}}},
+		{"nothing", nil},
+	}
+	for _, tc := range tests {
+		if results := Search(searchTestData, tc.query); !reflect.DeepEqual(results, tc.expected) {
+			t.Errorf("%q: want %v got %v", tc.query, tc.expected, results)
+		}
+	}
+
+	if results := Search(searchTestData, " "); len(results) != len(searchTestData) || results[0].Name != "zlib" {
+		t.Errorf("unexpected results of empty query: %v", results)
+	}
+}
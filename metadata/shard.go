@@ -0,0 +1,243 @@
+package metadata
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/goplus/llpkgstore/internal/file"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	ShardIndexFileName = "index.json"
+	ShardDirName       = "packages"
+
+	// shardCacheDirName is the subdirectory of the cache dir keeping the sharded layout,
+	// so that index.json and packages/ don't collide with other caches.
+	shardCacheDirName = "llpkgstore"
+
+	// shardConcurrency limits the concurrent fetches when updating all shards
+	shardConcurrency = 8
+)
+
+var (
+	// shardNameMatch matches the package names which are safe to be file names
+	shardNameMatch = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+-]*$`)
+
+	ErrShardMismatch = errors.New("shard doesn't match the index")
+)
+
+// Index is the index of the sharded layout, index.json.
+type Index struct {
+	// Packages maps the package names to the hex encoded SHA-256 of their shards,
+	// packages/{name}.json, which hold the Metadata of the packages.
+	Packages map[string]string `json:"packages"`
+}
+
+// WriteShards writes the metadata map to dir in the sharded layout.
+// The shards not in the metadata map any more are removed.
+func WriteShards(m MetadataMap, dir string) error {
+	shardDir := filepath.Join(dir, ShardDirName)
+	if err := os.MkdirAll(shardDir, 0755); err != nil {
+		return err
+	}
+
+	index := Index{Packages: make(map[string]string, len(m))}
+	for name, metadata := range m {
+		if !shardNameMatch.MatchString(name) {
+			return fmt.Errorf("invalid package name for shard: %q", name)
+		}
+		if metadata == nil {
+			continue
+		}
+		b, err := json.Marshal(metadata)
+		if err != nil {
+			return err
+		}
+		if err := file.WriteFileAtomic(filepath.Join(shardDir, name+".json"), b, 0644); err != nil {
+			return err
+		}
+		index.Packages[name] = shardHash(b)
+	}
+
+	// remove the stale shards
+	entries, err := os.ReadDir(shardDir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if _, exists := index.Packages[name]; ok && !exists {
+			if err := os.Remove(filepath.Join(shardDir, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+
+	b, err := json.Marshal(&index)
+	if err != nil {
+		return err
+	}
+	return file.WriteFileAtomic(filepath.Join(dir, ShardIndexFileName), b, 0644)
+}
+
+// ValidPackageName reports whether the package name is safe to be a file or directory name,
+// which is required by the sharded layout.
+func ValidPackageName(name string) bool {
+	return shardNameMatch.MatchString(name)
+}
+
+func shardHash(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// shardedSource is the source of the sharded layout,
+// the index is always refreshed, but only the shards being looked up are fetched.
+type shardedSource struct {
+	cacheDir string
+	baseURL  string
+	opts     []Options
+
+	index *Cache[Index]
+
+	mu     sync.RWMutex
+	shards map[string]*Cache[Metadata]
+}
+
+func newShardedSource(cacheDir string, opts ...Options) (*shardedSource, error) {
+	baseURL := newSettings(opts).baseURL
+	if baseURL == "" {
+		baseURL = remoteMetadataURL[:strings.LastIndex(remoteMetadataURL, "/")]
+	}
+	// mirrors are resolved against the base URL, for both the index and the shards
+	opts = append(slices.Clone(opts), WithBaseURL(baseURL))
+	cacheDir = filepath.Join(cacheDir, shardCacheDirName)
+
+	index, err := NewCache[Index](filepath.Join(cacheDir, ShardIndexFileName), joinURL(baseURL, ShardIndexFileName), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &shardedSource{
+		cacheDir: cacheDir,
+		baseURL:  baseURL,
+		opts:     opts,
+		index:    index,
+		shards:   make(map[string]*Cache[Metadata]),
+	}
+
+	// load the shards cached on disk
+	for name := range index.Data().Packages {
+		if !shardNameMatch.MatchString(name) {
+			continue
+		}
+		if _, err := os.Stat(s.shardPath(name)); err != nil {
+			continue
+		}
+		if shard, err := s.newShard(name); err == nil {
+			s.shards[name] = shard
+		}
+	}
+	return s, nil
+}
+
+func (s *shardedSource) shardPath(name string) string {
+	return filepath.Join(s.cacheDir, ShardDirName, name+".json")
+}
+
+// newShard loads the shard from disk, or fetches it if missing.
+func (s *shardedSource) newShard(name string) (*Cache[Metadata], error) {
+	remoteURL := joinURL(s.baseURL, ShardDirName+"/"+name+".json")
+	verify := func(body []byte) error {
+		if shardHash(body) != s.index.Data().Packages[name] {
+			return fmt.Errorf("%s: %w", name, ErrShardMismatch)
+		}
+		return nil
+	}
+	// shards are verified by the hashes in the index, which is signed
+	opts := append(slices.Clone(s.opts), withVerifier(verify), withoutSignature())
+	return NewCache[Metadata](s.shardPath(name), remoteURL, opts...)
+}
+
+// Data returns the metadata of the cached shards.
+func (s *shardedSource) Data() MetadataMap {
+	packages := s.index.Data().Packages
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	m := make(MetadataMap, len(s.shards))
+	for name, shard := range s.shards {
+		if _, ok := packages[name]; ok {
+			metadata := shard.Data()
+			m[name] = &metadata
+		}
+	}
+	return m
+}
+
+// Update refreshes the index and all shards in it.
+func (s *shardedSource) Update() error {
+	if err := s.index.Update(); err != nil {
+		return err
+	}
+
+	var group errgroup.Group
+	group.SetLimit(shardConcurrency)
+	for name, hash := range s.index.Data().Packages {
+		if !shardNameMatch.MatchString(name) {
+			continue
+		}
+		group.Go(func() error {
+			return s.updateShard(name, hash)
+		})
+	}
+	return group.Wait()
+}
+
+// UpdateByName refreshes the index and the shard of the package.
+// It does nothing more if the package isn't in the index.
+func (s *shardedSource) UpdateByName(name string) error {
+	if err := s.index.Update(); err != nil {
+		return err
+	}
+	hash, ok := s.index.Data().Packages[name]
+	if !ok || !shardNameMatch.MatchString(name) {
+		return nil
+	}
+	return s.updateShard(name, hash)
+}
+
+// updateShard fetches the shard if it's missing or doesn't match the hash in the index.
+// The hash is compared with the one of the fetched body, as the cached data may be
+// encoded differently from the published shard.
+func (s *shardedSource) updateShard(name, hash string) error {
+	s.mu.RLock()
+	shard := s.shards[name]
+	s.mu.RUnlock()
+
+	if shard == nil {
+		var err error
+		shard, err = s.newShard(name)
+		if err != nil {
+			return err
+		}
+		s.mu.Lock()
+		s.shards[name] = shard
+		s.mu.Unlock()
+	}
+
+	if shard.sum() == hash {
+		return nil
+	}
+	return shard.update(true)
+}
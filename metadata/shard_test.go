@@ -0,0 +1,195 @@
+package metadata
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// newShardServer serves the files in dir and records the requested paths
+func newShardServer(t *testing.T, dir string) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.URL.Path)
+		mu.Unlock()
+		b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(r.URL.Path)))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(b)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		ret := requests
+		requests = nil
+		return ret
+	}
+}
+
+func TestWriteShards(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ShardDirName), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// a stale shard
+	os.WriteFile(filepath.Join(dir, ShardDirName, "removed.json"), []byte("{}"), 0644)
+
+	if err := WriteShards(resolveTestData, dir); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, ShardDirName, "cjson.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, _ := os.ReadFile(filepath.Join(dir, ShardIndexFileName))
+	if string(index) != `{"packages":{"cjson":"`+shardHash(b)+`"}}` {
+		t.Errorf("unexpected index: %s", index)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ShardDirName, "removed.json")); err == nil {
+		t.Error("unexpected behavior: stale shard is kept")
+	}
+
+	if err := WriteShards(MetadataMap{"../escape": &Metadata{}}, dir); err == nil {
+		t.Error("unexpected behavior: invalid package name is accepted")
+	}
+}
+
+func TestMetadataMgr_Sharding(t *testing.T) {
+	storeDir := t.TempDir()
+	store := MetadataMap{
+		"cjson":   &Metadata{Versions: VersionMap{"1.7.18": {"v1.1.0"}}},
+		"libxml2": &Metadata{Versions: VersionMap{"2.13.4": {"v1.0.0"}}},
+		"zlib":    &Metadata{Versions: VersionMap{"1.3.1": {"v1.0.0"}}},
+	}
+	if err := WriteShards(store, storeDir); err != nil {
+		t.Fatal(err)
+	}
+	server, requests := newShardServer(t, storeDir)
+
+	cacheDir := t.TempDir()
+	mgr, err := newMetadataMgr(cacheDir, WithSharding(), WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := requests(); !reflect.DeepEqual(got, []string{"/index.json"}) {
+		t.Errorf("unexpected requests: %v", got)
+	}
+
+	// only the shard being looked up is fetched
+	if cVersion, err := mgr.CVerFromGoVer("cjson", "v1.1.0"); err != nil || cVersion != "1.7.18" {
+		t.Errorf("unexpected result: %s %v", cVersion, err)
+	}
+	if got := requests(); !reflect.DeepEqual(got, []string{"/index.json", "/packages/cjson.json"}) {
+		t.Errorf("unexpected requests: %v", got)
+	}
+	if exists, err := mgr.ModuleExists("unknown"); exists || err != nil {
+		t.Errorf("unexpected result: %v %v", exists, err)
+	}
+
+	// a new version is published, the shard is refetched as its hash changes
+	store["cjson"] = &Metadata{Versions: VersionMap{"1.7.18": {"v1.1.0", "v1.1.1"}}}
+	if err := WriteShards(store, storeDir); err != nil {
+		t.Fatal(err)
+	}
+	requests()
+	if goVersions, err := mgr.GoVersFromCVer("cjson", "1.7.18"); err != nil || len(goVersions) != 1 {
+		t.Errorf("unexpected result: %v %v", goVersions, err)
+	}
+	if latest, err := mgr.LatestGoVerFromCVer("cjson", "1.7.19"); err == nil {
+		t.Errorf("unexpected result: %v", latest)
+	}
+	if goVersions, err := mgr.GoVersFromCVer("cjson", "1.7.18"); err != nil || len(goVersions) != 2 {
+		t.Errorf("unexpected result: %v %v", goVersions, err)
+	}
+	if got := requests(); !reflect.DeepEqual(got, []string{"/index.json", "/packages/cjson.json"}) {
+		t.Errorf("unexpected requests: %v", got)
+	}
+
+	// the cached shards are loaded from disk
+	mgr, err = newMetadataMgr(cacheDir, WithSharding(), WithBaseURL(server.URL), WithOffline())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mgr.CVerFromGoVer("cjson", "v1.1.1"); err != nil {
+		t.Error(err)
+	}
+
+	// all shards are fetched for all metadata
+	mgr, err = newMetadataMgr(cacheDir, WithSharding(), WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, err := mgr.AllMetadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(all, store) {
+		t.Errorf("unexpected metadata: %v", all)
+	}
+
+	// a shard not matching the index is refused
+	os.WriteFile(filepath.Join(storeDir, ShardDirName, "zlib.json"), []byte(`{"versions":{"1.3.1":["v9.9.9"]}}`), 0644)
+	mgr, err = newMetadataMgr(t.TempDir(), WithSharding(), WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mgr.MetadataByName("zlib"); !errors.Is(err, ErrShardMismatch) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestMetadataMgr_ShardingBodyHash(t *testing.T) {
+	// shards published by other tools may be encoded differently from the cached data
+	storeDir := t.TempDir()
+	shard := []byte("{\n  \"versions\": {\n    \"1.7.18\": [\"v1.1.0\"]\n  }\n}\n")
+	if err := os.MkdirAll(filepath.Join(storeDir, ShardDirName), 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(storeDir, ShardDirName, "cjson.json"), shard, 0644)
+	os.WriteFile(filepath.Join(storeDir, ShardIndexFileName), []byte(`{"packages":{"cjson":"`+shardHash(shard)+`"}}`), 0644)
+	server, requests := newShardServer(t, storeDir)
+
+	cacheDir := t.TempDir()
+	mgr, err := newMetadataMgr(cacheDir, WithSharding(), WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mgr.CVerFromGoVer("cjson", "v1.1.0"); err != nil {
+		t.Error(err)
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, shardCacheDirName, ShardDirName, "cjson.json")); err != nil {
+		t.Errorf("unexpected behavior: shard isn't cached in its own directory: %v", err)
+	}
+
+	// the shard is up to date, only the index is refreshed
+	requests()
+	if latest, err := mgr.LatestGoVerFromCVer("cjson", "1.7.19"); err == nil {
+		t.Errorf("unexpected result: %v", latest)
+	}
+	if got := requests(); !reflect.DeepEqual(got, []string{"/index.json"}) {
+		t.Errorf("unexpected requests: %v", got)
+	}
+
+	// so does the one loaded from disk
+	mgr, err = newMetadataMgr(cacheDir, WithSharding(), WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests()
+	if latest, err := mgr.LatestGoVerFromCVer("cjson", "1.7.19"); err == nil {
+		t.Errorf("unexpected result: %v", latest)
+	}
+	if got := requests(); !reflect.DeepEqual(got, []string{"/index.json"}) {
+		t.Errorf("unexpected requests: %v", got)
+	}
+}
@@ -0,0 +1,66 @@
+package metadata
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// SignatureSuffix is appended to the name of a data file to get its detached signature file,
+// e.g. llpkgstore.json.sig
+const SignatureSuffix = ".sig"
+
+var ErrInvalidSignature = errors.New("signature verification failed")
+
+// Sign returns the detached signature of data,
+// which is the base64 encoded ed25519 signature followed by a newline.
+func Sign(key ed25519.PrivateKey, data []byte) []byte {
+	sig := ed25519.Sign(key, data)
+	return []byte(base64.StdEncoding.EncodeToString(sig) + "\n")
+}
+
+// Verify reports whether sig is a valid detached signature of data by any of keys,
+// it returns ErrInvalidSignature if not.
+func Verify(keys []ed25519.PublicKey, data, sig []byte) error {
+	raw, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(sig)))
+	if err != nil || len(raw) != ed25519.SignatureSize {
+		return fmt.Errorf("%w: malformed signature", ErrInvalidSignature)
+	}
+	for _, key := range keys {
+		if ed25519.Verify(key, data, raw) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// ParsePublicKey decodes a base64 encoded ed25519 public key.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key: bad length %d", len(raw))
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// ParsePrivateKey decodes a base64 encoded ed25519 private key,
+// either the 32-byte seed or the 64-byte key is accepted.
+func ParsePrivateKey(s string) (ed25519.PrivateKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(raw), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(raw), nil
+	default:
+		return nil, fmt.Errorf("invalid private key: bad length %d", len(raw))
+	}
+}
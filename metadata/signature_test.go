@@ -0,0 +1,57 @@
+package metadata
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+
+	data := []byte(`{"cjson":{"versions":{"1.7.18":["v1.0.0"]}}}`)
+	sig := Sign(priv, data)
+
+	if err := Verify([]ed25519.PublicKey{otherPub, pub}, data, sig); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := Verify([]ed25519.PublicKey{otherPub}, data, sig); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("unexpected behavior: signature by unknown key is accepted: %v", err)
+	}
+	if err := Verify([]ed25519.PublicKey{pub}, append(data, ' '), sig); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("unexpected behavior: tampered data is accepted: %v", err)
+	}
+	if err := Verify([]ed25519.PublicKey{pub}, data, []byte("not a signature")); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("unexpected behavior: malformed signature is accepted: %v", err)
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsedPub, err := ParsePublicKey(base64.StdEncoding.EncodeToString(pub))
+	if err != nil || !parsedPub.Equal(pub) {
+		t.Errorf("unexpected public key: %v", err)
+	}
+	for _, raw := range [][]byte{priv, priv.Seed()} {
+		parsed, err := ParsePrivateKey(base64.StdEncoding.EncodeToString(raw))
+		if err != nil || !parsed.Equal(priv) {
+			t.Errorf("unexpected private key: %v", err)
+		}
+	}
+
+	if _, err := ParsePublicKey(base64.StdEncoding.EncodeToString([]byte("short"))); err == nil {
+		t.Error("unexpected behavior: short public key is accepted")
+	}
+	if _, err := ParsePrivateKey("%%%"); err == nil {
+		t.Error("unexpected behavior: invalid base64 is accepted")
+	}
+}
@@ -3,6 +3,7 @@ package metadata
 import (
 	"errors"
 	"fmt"
+	"slices"
 
 	"golang.org/x/mod/semver"
 )
@@ -29,6 +30,12 @@ func (m *metadataMgr) LatestGoVer(name string) (string, error) {
 		return "", err
 	}
 
+	// Skip the retracted versions
+	allGoVersions, err = m.skipRetracted(name, allGoVersions)
+	if err != nil {
+		return "", err
+	}
+
 	if len(allGoVersions) == 0 {
 		return "", fmt.Errorf("no Go versions found for %s", name)
 	}
@@ -45,21 +52,28 @@ func (m *metadataMgr) LatestGoVerFromCVer(name, cVer string) (string, error) {
 	cKey := flatKey{name, cVer}
 
 	// Search for the latest Go version
-	goVersions, ok := m.flatCToGo[cKey]
+	goVersions, ok := m.goVersions(cKey)
 	if !ok {
 		// Try to update if not found
-		err := m.update()
+		err := m.updateByName(name)
 		if err != nil {
 			return "", err
 		}
 
 		// Try again
-		goVersions, ok = m.flatCToGo[cKey]
+		goVersions, ok = m.goVersions(cKey)
 		if !ok {
 			return "", fmt.Errorf("no version mappings for %s %s", name, cVer)
 		}
 	}
 
+	// Skip the retracted versions, which makes a copy,
+	// the versions are shared with other goroutines
+	goVersions, err := m.skipRetracted(name, goVersions)
+	if err != nil {
+		return "", err
+	}
+
 	if len(goVersions) > 0 {
 		semver.Sort(goVersions)
 		latestGoVersion := goVersions[len(goVersions)-1]
@@ -76,16 +90,16 @@ func (m *metadataMgr) GoVersFromCVer(name, cVer string) ([]string, error) {
 	cKey := flatKey{name, cVer}
 
 	// Search for the Go versions
-	versions, ok := m.flatCToGo[cKey]
+	versions, ok := m.goVersions(cKey)
 	if !ok {
 		// Try to update if not found
-		err := m.update()
+		err := m.updateByName(name)
 		if err != nil {
 			return nil, err
 		}
 
 		// Try again
-		versions, ok = m.flatCToGo[cKey]
+		versions, ok = m.goVersions(cKey)
 		if !ok {
 			return nil, fmt.Errorf("no version mappings for %s %s", name, cVer)
 		}
@@ -104,16 +118,16 @@ func (m *metadataMgr) CVerFromGoVer(name, goVer string) (string, error) {
 	goKey := flatKey{name, goVer}
 
 	// Search for the C version in the cached flat hash
-	cVersion, ok := m.flatGoToC[goKey]
+	cVersion, ok := m.cVersion(goKey)
 	if !ok {
 		// Update if not found
-		err := m.update()
+		err := m.updateByName(name)
 		if err != nil {
 			return "", err
 		}
 
 		// Try again
-		cVersion, ok = m.flatGoToC[goKey]
+		cVersion, ok = m.cVersion(goKey)
 		if !ok {
 			return "", fmt.Errorf("no C version found for %s %s", name, goVer)
 		}
@@ -133,21 +147,15 @@ func (m *metadataMgr) AllGoVersFromName(name string) ([]string, error) {
 			return nil, err
 		}
 
-		// Extract Go versions
-		goVersions := make([]string, 0, len(m.flatGoToC))
-		for goVersionKey, _ := range m.flatGoToC {
-			if goVersionKey.name == name {
-				goVersions = append(goVersions, goVersionKey.version)
-			}
-		}
-
-		return goVersions, nil
+		// Extract Go versions, return a copy
+		nameIndex, _ := m.flatNameIndex(name)
+		return slices.Clone(nameIndex.goVersions), nil
 	}
 
 	goVersions, err := queryFunc(name)
 	if err != nil {
 		// update and try again
-		err := m.update()
+		err := m.updateByName(name)
 		if err != nil {
 			return nil, err
 		}
@@ -172,21 +180,15 @@ func (m *metadataMgr) AllCVersFromName(name string) ([]string, error) {
 			return nil, err
 		}
 
-		// Extract C versions
-		cVersions := make([]string, 0, len(m.flatCToGo))
-		for cVersionKey, _ := range m.flatCToGo {
-			if cVersionKey.name == name {
-				cVersions = append(cVersions, cVersionKey.version)
-			}
-		}
-
-		return cVersions, nil
+		// Extract C versions, return a copy
+		nameIndex, _ := m.flatNameIndex(name)
+		return slices.Clone(nameIndex.cVersions), nil
 	}
 
 	cVersions, err := queryFunc(name)
 	if err != nil {
 		// update and try again
-		err := m.update()
+		err := m.updateByName(name)
 		if err != nil {
 			return nil, err
 		}
@@ -200,6 +202,29 @@ func (m *metadataMgr) AllCVersFromName(name string) ([]string, error) {
 	return cVersions, nil
 }
 
+// skipRetracted returns a copy of goVersions without the retracted ones of the module
+func (m *metadataMgr) skipRetracted(name string, goVersions []string) ([]string, error) {
+	metadata, err := m.cachedMetadataByName(name)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(slices.Clone(goVersions), metadata.IsRetracted), nil
+}
+
+// goVersions looks up the Go versions mapped from the C version in the flat hash
+func (m *metadataMgr) goVersions(cKey flatKey) ([]string, bool) {
+	flatCToGo, _ := m.flatVersionMaps()
+	goVersions, ok := flatCToGo[cKey]
+	return goVersions, ok
+}
+
+// cVersion looks up the C version mapped from the Go version in the flat hash
+func (m *metadataMgr) cVersion(goKey flatKey) (string, bool) {
+	_, flatGoToC := m.flatVersionMaps()
+	cVersion, ok := flatGoToC[goKey]
+	return cVersion, ok
+}
+
 type flatKey struct {
 	name, version string
 }
@@ -207,3 +232,49 @@ type flatKey struct {
 func (m *flatKey) String(name, version string) string {
 	return fmt.Sprintf("%s/%s", m.name, m.version)
 }
+
+// Resolve resolves the version query against the up-to-date metadata,
+// see the package function Resolve for the query syntax.
+func (m *metadataMgr) Resolve(query string) (Resolved, error) {
+	resolved, err := Resolve(m.allCachedMetadata(), query)
+	if errors.Is(err, ErrMetadataNotInCache) || errors.Is(err, ErrNoMatchingVersion) {
+		// Try to update if not found
+		err := m.updateByName(queryName(query))
+		if err != nil {
+			return Resolved{}, err
+		}
+
+		// Try again
+		return Resolve(m.allCachedMetadata(), query)
+	}
+	return resolved, err
+}
+
+// Gets the release details based on the module name and Go version
+func (m *metadataMgr) ReleaseInfo(name, goVer string) (Release, error) {
+	queryFunc := func() (Release, error) {
+		metadata, err := m.cachedMetadataByName(name)
+		if err != nil {
+			return Release{}, err
+		}
+		release := metadata.Releases[goVer]
+		if release == nil {
+			return Release{}, fmt.Errorf("no release info for %s %s: %w", name, goVer, ErrMetadataNotInCache)
+		}
+		// Return a copy
+		ret := *release
+		ret.Assets = slices.Clone(release.Assets)
+		return ret, nil
+	}
+
+	release, err := queryFunc()
+	if errors.Is(err, ErrMetadataNotInCache) {
+		// update and try again
+		err := m.updateByName(name)
+		if err != nil {
+			return Release{}, err
+		}
+		return queryFunc()
+	}
+	return release, err
+}
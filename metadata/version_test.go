@@ -2,11 +2,13 @@ package metadata
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"slices"
 	"testing"
+	"time"
 )
 
 // Define more enriched test data
@@ -31,7 +33,7 @@ func setupTestEnv(t *testing.T, testData MetadataMap) (*metadataMgr, func()) {
 	remoteMetadataURL = server.URL
 
 	tmpDir := t.TempDir()
-	mgr, err := NewMetadataMgr(tmpDir)
+	mgr, err := newMetadataMgr(tmpDir)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -247,3 +249,70 @@ func TestAllCVersFromName(t *testing.T) {
 		t.Fatal("Expected error for non-existent module")
 	}
 }
+
+// TestRetracted tests that retracted versions are never selected as the latest version
+func TestRetracted(t *testing.T) {
+	mgr, cleanup := setupTestEnv(t, MetadataMap{
+		"test-module": &Metadata{
+			Versions: VersionMap{
+				"1.7.18": {"v1.2.0", "v1.2.1"},
+				"1.8.0":  {"v1.4.0"},
+			},
+			Retracted: map[GoVersion]string{
+				"v1.2.1": "broken binary",
+				"v1.4.0": "broken binary",
+			},
+		},
+	})
+	defer cleanup()
+
+	if latest, err := mgr.LatestGoVer("test-module"); err != nil || latest != "v1.2.0" {
+		t.Errorf("unexpected LatestGoVer: %s %v", latest, err)
+	}
+	if latest, err := mgr.LatestCVer("test-module"); err != nil || latest != "1.7.18" {
+		t.Errorf("unexpected LatestCVer: %s %v", latest, err)
+	}
+	if latest, err := mgr.LatestGoVerFromCVer("test-module", "1.7.18"); err != nil || latest != "v1.2.0" {
+		t.Errorf("unexpected LatestGoVerFromCVer: %s %v", latest, err)
+	}
+	if _, err := mgr.LatestGoVerFromCVer("test-module", "1.8.0"); err == nil {
+		t.Error("unexpected behavior: retracted version is selected")
+	}
+
+	if resolved, err := mgr.Resolve("test-module@>=1.7"); err != nil || resolved.GoVersion != "v1.2.0" {
+		t.Errorf("unexpected Resolve: %+v %v", resolved, err)
+	}
+	// the exact version is still available
+	if resolved, err := mgr.Resolve("github.com/goplus/llpkg/test-module@v1.4.0"); err != nil || resolved.CVersion != "1.8.0" {
+		t.Errorf("unexpected Resolve: %+v %v", resolved, err)
+	}
+}
+
+// TestReleaseInfo tests the implementation of the ReleaseInfo function
+func TestReleaseInfo(t *testing.T) {
+	release := &Release{
+		Time:   time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		Commit: "c0ffee",
+		Assets: []Asset{{Name: "test-module_linux_amd64.zip", Platform: "linux/amd64", SHA256: "00"}},
+	}
+	mgr, cleanup := setupTestEnv(t, MetadataMap{
+		"test-module": &Metadata{
+			Versions: VersionMap{"1.7.18": {"v1.2.0", "v1.2.1"}},
+			Releases: map[GoVersion]*Release{"v1.2.0": release},
+		},
+	})
+	defer cleanup()
+
+	info, err := mgr.ReleaseInfo("test-module", "v1.2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(info, *release) {
+		t.Errorf("unexpected release info: %+v", info)
+	}
+
+	// released before the details are recorded
+	if _, err := mgr.ReleaseInfo("test-module", "v1.2.1"); !errors.Is(err, ErrMetadataNotInCache) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}